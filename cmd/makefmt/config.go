@@ -0,0 +1,223 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/donaldgifford/makefmt/internal/config"
+	"github.com/donaldgifford/makefmt/internal/formatter"
+	"github.com/donaldgifford/makefmt/internal/rules"
+	"github.com/donaldgifford/makefmt/internal/runner"
+)
+
+// runConfig runs a makefmt config subcommand.
+func runConfig(args []string) int {
+	if len(args) == 0 {
+		configUsage(os.Stderr)
+		return runner.ExitError
+	}
+
+	switch args[0] {
+	case "validate":
+		return runConfigValidate(args[1:])
+	case "schema":
+		return runConfigSchema(args[1:])
+	case "init":
+		return runConfigInit(args[1:])
+	case "show":
+		return runConfigShow(args[1:])
+	case "-h", "-help", "--help", "help":
+		configUsage(os.Stdout)
+		return runner.ExitOK
+	default:
+		fmt.Fprintf(os.Stderr, "makefmt config: unknown command %q\n", args[0])
+		configUsage(os.Stderr)
+		return runner.ExitError
+	}
+}
+
+// runConfigValidate checks the given config file, or the config files
+// that apply in the current directory, and reports every problem. The
+// MAKEFMT_* environment variables are checked as well.
+func runConfigValidate(args []string) int {
+	flags := flag.NewFlagSet("makefmt config validate", flag.ContinueOnError)
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: makefmt config validate [file]\n")
+	}
+	if err := flags.Parse(args); err != nil {
+		return runner.ExitError
+	}
+
+	code := runner.ExitOK
+	ruleNames := formatter.RuleNames(rules.FormatRules())
+	env := envConfig("")
+	if err := config.NewResolver("", ruleNames).Set(env.settings...); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		code = runner.ExitFormatDiff
+	}
+
+	paths := flags.Args()
+	switch {
+	case len(paths) > 0:
+	case env.path != "":
+		paths = []string{env.path}
+	default:
+		chain, err := config.DiscoverChain(".")
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "makefmt: %v\n", err)
+			return runner.ExitError
+		}
+		if len(chain) == 0 {
+			fmt.Println("no config file found; using defaults")
+			return code
+		}
+		paths = chain
+	}
+
+	for _, path := range paths {
+		if _, err := config.NewResolver(path, ruleNames).ForDir("."); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			code = runner.ExitFormatDiff
+			continue
+		}
+		fmt.Printf("%s: ok\n", path)
+	}
+	return code
+}
+
+// runConfigSchema prints the JSON Schema for config files.
+func runConfigSchema(args []string) int {
+	if len(args) > 0 {
+		fmt.Fprintf(os.Stderr, "Usage: makefmt config schema\n")
+		return runner.ExitError
+	}
+
+	schema, err := config.Schema()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "makefmt: %v\n", err)
+		return runner.ExitError
+	}
+	if _, err := os.Stdout.Write(schema); err != nil {
+		fmt.Fprintf(os.Stderr, "makefmt: %v\n", err)
+		return runner.ExitError
+	}
+	return runner.ExitOK
+}
+
+// runConfigInit writes a commented config file listing every option.
+func runConfigInit(args []string) int {
+	flags := flag.NewFlagSet("makefmt config init", flag.ContinueOnError)
+	force := flags.Bool("force", false, "overwrite an existing file")
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: makefmt config init [-force] [file]\n")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return runner.ExitError
+	}
+	if flags.NArg() > 1 {
+		flags.Usage()
+		return runner.ExitError
+	}
+
+	path := "makefmt.yml"
+	if flags.NArg() == 1 {
+		path = flags.Arg(0)
+	}
+
+	mode := os.O_WRONLY | os.O_CREATE | os.O_EXCL
+	if *force {
+		mode = os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+	}
+	f, err := os.OpenFile(path, mode, 0o644)
+	if errors.Is(err, os.ErrExist) {
+		fmt.Fprintf(os.Stderr, "makefmt: %s already exists; use -force to overwrite it\n", path)
+		return runner.ExitError
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "makefmt: %v\n", err)
+		return runner.ExitError
+	}
+
+	_, err = f.Write(config.Template())
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "makefmt: writing %s: %v\n", path, err)
+		return runner.ExitError
+	}
+	fmt.Printf("wrote %s\n", path)
+	return runner.ExitOK
+}
+
+// runConfigShow prints the effective config for a file and the source of
+// each setting.
+func runConfigShow(args []string) int {
+	flags := flag.NewFlagSet("makefmt config show", flag.ContinueOnError)
+	configPath := flags.String("config", "", "path to config file")
+	var settings []config.Setting
+	flags.Func("set", "override a config setting, as `key=value` (repeatable)", func(s string) error {
+		setting, err := config.ParseSetting(s)
+		if err != nil {
+			return err
+		}
+		settings = append(settings, setting)
+		return nil
+	})
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: makefmt config show [-config path] [-set key=value] [file]\n")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return runner.ExitError
+	}
+	if flags.NArg() > 1 {
+		flags.Usage()
+		return runner.ExitError
+	}
+
+	// Without a file, show the config for a Makefile in the current
+	// directory.
+	path := "Makefile"
+	if flags.NArg() == 1 {
+		path = flags.Arg(0)
+	}
+
+	env := envConfig(*configPath)
+	resolver := config.NewResolver(env.path, formatter.RuleNames(rules.FormatRules()))
+	if err := resolver.Set(append(env.settings, settings...)...); err != nil {
+		fmt.Fprintf(os.Stderr, "makefmt: %v\n", err)
+		return runner.ExitError
+	}
+	cfg, prov, err := resolver.Explain(path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "makefmt: %v\n", err)
+		return runner.ExitError
+	}
+	out, err := config.Show(cfg, prov)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "makefmt: %v\n", err)
+		return runner.ExitError
+	}
+	if _, err := os.Stdout.Write(out); err != nil {
+		fmt.Fprintf(os.Stderr, "makefmt: %v\n", err)
+		return runner.ExitError
+	}
+	return runner.ExitOK
+}
+
+func configUsage(w io.Writer) {
+	fmt.Fprintf(w, `Usage: makefmt config <command> [arguments]
+
+Commands:
+  validate [file]    check config files for unknown fields and invalid values
+  schema             print the JSON Schema for config files
+  init [file]        write a commented config file with every option
+  show [file]        print the effective config for a file and where each
+                     setting came from
+`)
+}
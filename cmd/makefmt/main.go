@@ -6,8 +6,11 @@ import (
 	"fmt"
 	"os"
 
-	_ "github.com/donaldgifford/makefmt/internal/rules" // Register rules via init().
+	"github.com/donaldgifford/makefmt/internal/config"
+	"github.com/donaldgifford/makefmt/internal/lsp"
+	"github.com/donaldgifford/makefmt/internal/rules"
 	"github.com/donaldgifford/makefmt/internal/runner"
+	"github.com/donaldgifford/makefmt/pkg/diff"
 )
 
 // Build-time variables set via ldflags.
@@ -18,13 +21,51 @@ var (
 )
 
 func main() {
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "lsp":
+			os.Exit(runLSP())
+		case "config":
+			os.Exit(runConfig(os.Args[2:]))
+		case "rules":
+			os.Exit(runRules(os.Args[2:]))
+		}
+	}
+
 	check := flag.Bool("check", false, "exit 1 if any file is not formatted")
 	diffFlag := flag.Bool("diff", false, "print unified diff of changes")
+	edits := flag.Bool("edits", false, "print changes as JSON text edits")
 	write := flag.Bool("w", false, "write result to file")
 	configPath := flag.String("config", "", "path to config file")
 	quiet := flag.Bool("q", false, "suppress informational output")
 	verbose := flag.Bool("v", false, "print files as they are processed")
 	showVersion := flag.Bool("version", false, "print version and exit")
+	changedSince := flag.String("changed-since", "", "only process Makefiles changed since git `ref`")
+	staged := flag.Bool("staged", false, "only process Makefiles with staged git changes")
+	linesChangedOnly := flag.Bool("lines-changed-only", false, "only apply formatting to lines changed in git")
+
+	var lines []diff.LineRange
+	flag.Func("lines", "only apply formatting to lines `start:end` (repeatable)", func(s string) error {
+		r, err := runner.ParseLineRange(s)
+		if err != nil {
+			return err
+		}
+		lines = append(lines, r)
+		return nil
+	})
+
+	var settings []config.Setting
+	flag.Func("set", "override a config setting, as `key=value` (repeatable)", func(s string) error {
+		setting, err := config.ParseSetting(s)
+		if err != nil {
+			return err
+		}
+		settings = append(settings, setting)
+		return nil
+	})
+	settingFlag("max-blank-lines", "formatter.max_blank_lines", "set max_blank_lines to `n`", &settings)
+	settingFlag("assignment-spacing", "formatter.assignment_spacing", "set assignment_spacing to `mode`", &settings)
+	settingBoolFlag("indent-conditionals", "formatter.indent_conditionals", "set indent_conditionals", &settings)
 
 	flag.Usage = usage
 	flag.Parse()
@@ -34,24 +75,100 @@ func main() {
 		return
 	}
 
+	env := envConfig(*configPath)
+
 	opts := &runner.Options{
 		Files:      flag.Args(),
 		Check:      *check,
 		Diff:       *diffFlag,
+		Edits:      *edits,
 		Write:      *write,
-		ConfigPath: *configPath,
+		ConfigPath: env.path,
 		Quiet:      *quiet,
 		Verbose:    *verbose,
+
+		ChangedSince:     *changedSince,
+		Staged:           *staged,
+		LinesChangedOnly: *linesChangedOnly,
+		Lines:            lines,
+
+		// Flags are applied after, and so take precedence over, the
+		// environment.
+		Settings: append(env.settings, settings...),
 	}
 
 	os.Exit(runner.Run(opts))
 }
 
+// settingFlag defines a flag that sets the config setting key, appending
+// it to settings in command-line order.
+func settingFlag(name, key, usage string, settings *[]config.Setting) {
+	flag.Func(name, usage, func(value string) error {
+		*settings = append(*settings, config.Setting{Key: key, Value: value, Source: "--" + name})
+		return nil
+	})
+}
+
+// settingBoolFlag is settingFlag for a boolean setting, which may be
+// given without a value.
+func settingBoolFlag(name, key, usage string, settings *[]config.Setting) {
+	flag.BoolFunc(name, usage, func(value string) error {
+		*settings = append(*settings, config.Setting{Key: key, Value: value, Source: "--" + name})
+		return nil
+	})
+}
+
+// envSettings is the configuration given in the environment.
+type envSettings struct {
+	path     string
+	settings []config.Setting
+}
+
+// envConfig reads MAKEFMT_CONFIG and MAKEFMT_<FIELD> variables. An
+// explicit configPath from the command line takes precedence over
+// MAKEFMT_CONFIG. Warnings about misspelled variables are printed to
+// stderr.
+func envConfig(configPath string) envSettings {
+	settings, warnings := config.EnvSettings(os.Environ())
+	for _, w := range warnings {
+		fmt.Fprintf(os.Stderr, "makefmt: warning: %s\n", w)
+	}
+	if configPath == "" {
+		configPath = os.Getenv(config.EnvConfigPath)
+	}
+	return envSettings{path: configPath, settings: settings}
+}
+
+// runLSP serves the Language Server Protocol on stdin and stdout.
+func runLSP() int {
+	env := envConfig("")
+	srv := &lsp.Server{
+		Rules:      rules.FormatRules(),
+		ConfigPath: env.path,
+		Settings:   env.settings,
+		Version:    version,
+	}
+	if err := srv.Serve(os.Stdin, os.Stdout); err != nil {
+		fmt.Fprintf(os.Stderr, "makefmt lsp: %v\n", err)
+		return runner.ExitError
+	}
+	return runner.ExitOK
+}
+
 func usage() {
 	fmt.Fprintf(os.Stderr, `Usage: makefmt [flags] [files...]
+       makefmt lsp
+       makefmt config <command>
+       makefmt rules list
 
 Format Makefile(s). With no files, reads from stdin.
 
+Commands:
+  lsp       run a Language Server Protocol server on stdin/stdout
+  config    validate, init or show config files, or print their schema
+            (see makefmt config help)
+  rules     list formatting rules and their state
+
 Flags:
 `)
 	flag.PrintDefaults()
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+
+	"github.com/donaldgifford/makefmt/internal/config"
+	"github.com/donaldgifford/makefmt/internal/formatter"
+	"github.com/donaldgifford/makefmt/internal/rules"
+	"github.com/donaldgifford/makefmt/internal/runner"
+)
+
+// runRules runs a makefmt rules subcommand.
+func runRules(args []string) int {
+	if len(args) == 0 {
+		rulesUsage(os.Stderr)
+		return runner.ExitError
+	}
+
+	switch args[0] {
+	case "list":
+		return runRulesList(args[1:])
+	case "-h", "-help", "--help", "help":
+		rulesUsage(os.Stdout)
+		return runner.ExitOK
+	default:
+		fmt.Fprintf(os.Stderr, "makefmt rules: unknown command %q\n", args[0])
+		rulesUsage(os.Stderr)
+		return runner.ExitError
+	}
+}
+
+// runRulesList prints every formatting rule in execution order, whether
+// it is enabled by default, and whether it is enabled for a file.
+func runRulesList(args []string) int {
+	flags := flag.NewFlagSet("makefmt rules list", flag.ContinueOnError)
+	configPath := flags.String("config", "", "path to config file")
+	var settings []config.Setting
+	flags.Func("set", "override a config setting, as `key=value` (repeatable)", func(s string) error {
+		setting, err := config.ParseSetting(s)
+		if err != nil {
+			return err
+		}
+		settings = append(settings, setting)
+		return nil
+	})
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: makefmt rules list [-config path] [-set key=value] [file]\n")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return runner.ExitError
+	}
+	if flags.NArg() > 1 {
+		flags.Usage()
+		return runner.ExitError
+	}
+
+	// Without a file, show the state for a Makefile in the current
+	// directory.
+	path := "Makefile"
+	if flags.NArg() == 1 {
+		path = flags.Arg(0)
+	}
+
+	env := envConfig(*configPath)
+	resolver := config.NewResolver(env.path, formatter.RuleNames(rules.FormatRules()))
+	if err := resolver.Set(append(env.settings, settings...)...); err != nil {
+		fmt.Fprintf(os.Stderr, "makefmt: %v\n", err)
+		return runner.ExitError
+	}
+	cfg, err := resolver.ForFile(path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "makefmt: %v\n", err)
+		return runner.ExitError
+	}
+
+	defaults := config.DefaultConfig()
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tDEFAULT\tSTATE\tDESCRIPTION")
+	for _, rule := range rules.FormatRules() {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", rule.Name(),
+			ruleState(formatter.Enabled(rule, &defaults.Formatter)),
+			ruleState(formatter.Enabled(rule, &cfg.Formatter)),
+			rule.Description())
+	}
+	if err := w.Flush(); err != nil {
+		fmt.Fprintf(os.Stderr, "makefmt: %v\n", err)
+		return runner.ExitError
+	}
+	return runner.ExitOK
+}
+
+func ruleState(enabled bool) string {
+	if enabled {
+		return "enabled"
+	}
+	return "disabled"
+}
+
+func rulesUsage(w io.Writer) {
+	fmt.Fprintf(w, `Usage: makefmt rules <command> [arguments]
+
+Commands:
+  list [file]    list formatting rules and whether each is enabled for file
+`)
+}
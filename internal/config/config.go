@@ -3,8 +3,19 @@ package config
 
 // Config is the top-level configuration.
 type Config struct {
+	// Root stops config discovery at the directory containing this file.
+	Root bool `yaml:"root"`
+
+	// Extends names a built-in preset or a config file, relative to this
+	// file, that is loaded before this file's settings.
+	Extends string `yaml:"extends"`
+
 	Formatter FormatterConfig `yaml:"formatter"`
 	Lint      LintConfig      `yaml:"lint"`
+
+	// Overrides apply settings to files matching glob patterns. Overrides
+	// from every loaded config file are kept, outermost first.
+	Overrides []Override `yaml:"overrides"`
 }
 
 // FormatterConfig holds all formatter settings.
@@ -23,6 +34,22 @@ type FormatterConfig struct {
 	IndentConditionals          bool   `yaml:"indent_conditionals"`
 	ConditionalIndent           int    `yaml:"conditional_indent"`
 	RecipePrefix                string `yaml:"recipe_prefix"`
+	ExpandInlineRecipes         bool   `yaml:"expand_inline_recipes"`
+	AlignInlineComments         bool   `yaml:"align_inline_comments"`
+	MaxLineLength               int    `yaml:"max_line_length"`
+	ContinuationIndent          int    `yaml:"continuation_indent"`
+	IndentContinuations         bool   `yaml:"indent_continuations"`
+
+	// ListVariables holds variable-name patterns (*_SOURCES) whose values
+	// are laid out as lists by ListLayout.
+	ListVariables   []string `yaml:"list_variables"`
+	ListLayout      string   `yaml:"list_layout"`
+	SortListItems   bool     `yaml:"sort_list_items"`
+	DedupeListItems bool     `yaml:"dedupe_list_items"`
+
+	// Rules turns formatting rules off by name. A rule runs when its own
+	// settings turn it on and it is not set to false here.
+	Rules map[string]bool `yaml:"rules"`
 }
 
 // LintConfig holds lint rule settings (post-MVP placeholder).
@@ -49,6 +76,14 @@ func DefaultConfig() *Config {
 			IndentConditionals:          true,
 			ConditionalIndent:           2,
 			RecipePrefix:                "preserve",
+			ExpandInlineRecipes:         false,
+			AlignInlineComments:         false,
+			MaxLineLength:               0,
+			ContinuationIndent:          4,
+			IndentContinuations:         false,
+			ListLayout:                  "expand",
+			SortListItems:               false,
+			DedupeListItems:             false,
 		},
 	}
 }
@@ -3,6 +3,9 @@ package config
 import (
 	"os"
 	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
 	"testing"
 )
 
@@ -97,7 +100,7 @@ func TestLoadNoConfigReturnsDefaults(t *testing.T) {
 	}
 
 	want := DefaultConfig()
-	if cfg.Formatter != want.Formatter {
+	if !reflect.DeepEqual(cfg.Formatter, want.Formatter) {
 		t.Errorf("expected default config, got %+v", cfg.Formatter)
 	}
 }
@@ -269,7 +272,7 @@ func TestLoadEmptyFile(t *testing.T) {
 
 	// Empty file should result in all defaults.
 	want := DefaultConfig()
-	if cfg.Formatter != want.Formatter {
+	if !reflect.DeepEqual(cfg.Formatter, want.Formatter) {
 		t.Errorf("expected default config for empty file, got %+v", cfg.Formatter)
 	}
 }
@@ -300,3 +303,258 @@ func TestLoadLintSection(t *testing.T) {
 		t.Errorf("Lint.Exclude: got %v, want [vendor/**]", cfg.Lint.Exclude)
 	}
 }
+
+// writeConfig writes a config file, creating parent directories.
+func writeConfig(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDiscoverChainStopsAtRepoRoot(t *testing.T) {
+	outer := t.TempDir()
+	repo := filepath.Join(outer, "repo")
+	sub := filepath.Join(repo, "services", "foo")
+
+	writeConfig(t, filepath.Join(outer, "makefmt.yml"), "formatter:\n  tab_width: 2\n")
+	writeConfig(t, filepath.Join(repo, "makefmt.yml"), "formatter:\n  max_blank_lines: 1\n")
+	writeConfig(t, filepath.Join(sub, ".makefmt.yml"), "formatter:\n  tab_width: 8\n")
+	if err := os.Mkdir(filepath.Join(repo, ".git"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	chain, err := DiscoverChain(sub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{filepath.Join(repo, "makefmt.yml"), filepath.Join(sub, ".makefmt.yml")}
+	if !slices.Equal(chain, want) {
+		t.Errorf("DiscoverChain = %v, want %v", chain, want)
+	}
+
+	// A directory between sub and the repo root without a config is skipped.
+	chain, err = DiscoverChain(filepath.Join(repo, "services"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{filepath.Join(repo, "makefmt.yml")}; !slices.Equal(chain, want) {
+		t.Errorf("DiscoverChain(services) = %v, want %v", chain, want)
+	}
+}
+
+func TestDiscoverChainRootMarker(t *testing.T) {
+	dir := t.TempDir()
+	sub := filepath.Join(dir, "sub")
+
+	writeConfig(t, filepath.Join(dir, "makefmt.yml"), "formatter:\n  tab_width: 2\n")
+	writeConfig(t, filepath.Join(sub, "makefmt.yml"), "root: true\nformatter:\n  tab_width: 8\n")
+
+	chain, err := DiscoverChain(sub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{filepath.Join(sub, "makefmt.yml")}; !slices.Equal(chain, want) {
+		t.Errorf("DiscoverChain = %v, want %v", chain, want)
+	}
+}
+
+func TestLoadDirLayersConfigs(t *testing.T) {
+	repo := t.TempDir()
+	sub := filepath.Join(repo, "sub")
+
+	if err := os.Mkdir(filepath.Join(repo, ".git"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	writeConfig(t, filepath.Join(repo, "makefmt.yml"), "formatter:\n  max_blank_lines: 1\n  tab_width: 2\n")
+	writeConfig(t, filepath.Join(sub, "makefmt.yml"), "formatter:\n  tab_width: 8\n")
+
+	cfg, err := LoadDir(sub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Formatter.TabWidth != 8 {
+		t.Errorf("TabWidth: got %d, want 8 (nearest config)", cfg.Formatter.TabWidth)
+	}
+	if cfg.Formatter.MaxBlankLines != 1 {
+		t.Errorf("MaxBlankLines: got %d, want 1 (inherited from repo root)", cfg.Formatter.MaxBlankLines)
+	}
+	if cfg.Formatter.AssignmentSpacing != "space" {
+		t.Errorf("AssignmentSpacing: got %q, want %q (default)", cfg.Formatter.AssignmentSpacing, "space")
+	}
+}
+
+func TestResolverPerSubtree(t *testing.T) {
+	repo := t.TempDir()
+	if err := os.Mkdir(filepath.Join(repo, ".git"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	writeConfig(t, filepath.Join(repo, "a", "makefmt.yml"), "formatter:\n  tab_width: 2\n")
+	writeConfig(t, filepath.Join(repo, "b", "makefmt.yml"), "formatter:\n  tab_width: 8\n")
+
+	r := NewResolver("", nil)
+	cfgA, err := r.ForFile(filepath.Join(repo, "a", "Makefile"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfgB, err := r.ForFile(filepath.Join(repo, "b", "Makefile"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfgRoot, err := r.ForFile(filepath.Join(repo, "Makefile"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if cfgA.Formatter.TabWidth != 2 || cfgB.Formatter.TabWidth != 8 || cfgRoot.Formatter.TabWidth != 4 {
+		t.Errorf("TabWidth: a=%d b=%d root=%d, want 2, 8, 4",
+			cfgA.Formatter.TabWidth, cfgB.Formatter.TabWidth, cfgRoot.Formatter.TabWidth)
+	}
+
+	// Explicit config overrides discovery everywhere.
+	explicit := filepath.Join(repo, "explicit.yml")
+	writeConfig(t, explicit, "formatter:\n  tab_width: 3\n")
+	r = NewResolver(explicit, nil)
+	cfgA, err = r.ForFile(filepath.Join(repo, "a", "Makefile"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfgA.Formatter.TabWidth != 3 {
+		t.Errorf("explicit TabWidth: got %d, want 3", cfgA.Formatter.TabWidth)
+	}
+}
+
+func TestLoadExtendsPreset(t *testing.T) {
+	for _, name := range Presets() {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "makefmt.yml")
+			writeConfig(t, path, "extends: "+name+"\n")
+
+			if _, err := Load(path); err != nil {
+				t.Fatalf("Load: %v", err)
+			}
+		})
+	}
+
+	path := filepath.Join(t.TempDir(), "makefmt.yml")
+	writeConfig(t, path, "extends: gnu\nformatter:\n  max_blank_lines: 3\n")
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Formatter.TabWidth != 8 {
+		t.Errorf("TabWidth: got %d, want 8 (from preset)", cfg.Formatter.TabWidth)
+	}
+	if cfg.Formatter.IndentConditionals {
+		t.Error("IndentConditionals: got true, want false (from preset)")
+	}
+	if cfg.Formatter.MaxBlankLines != 3 {
+		t.Errorf("MaxBlankLines: got %d, want 3 (local override)", cfg.Formatter.MaxBlankLines)
+	}
+	if cfg.Formatter.BackslashColumn != 79 {
+		t.Errorf("BackslashColumn: got %d, want 79 (default)", cfg.Formatter.BackslashColumn)
+	}
+	if cfg.Extends != "" {
+		t.Errorf("Extends: got %q, want empty after loading", cfg.Extends)
+	}
+}
+
+func TestStrictPreset(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "makefmt.yml")
+	writeConfig(t, path, "extends: strict\n")
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// List settings only apply to the variables a project names in
+	// list_variables, so strict cannot turn them on by itself.
+	listSettings := map[string]bool{"sort_list_items": true, "dedupe_list_items": true}
+
+	strict := reflect.ValueOf(cfg.Formatter)
+	defaults := reflect.ValueOf(DefaultConfig().Formatter)
+	for i := range strict.NumField() {
+		key := strings.Split(strict.Type().Field(i).Tag.Get("yaml"), ",")[0]
+		desc := descriptions["formatter."+key]
+		reserved := strings.Contains(desc, "reserved for future use")
+		got, def := strict.Field(i), defaults.Field(i)
+
+		switch {
+		case got.Kind() == reflect.Bool && reserved:
+			if got.Bool() {
+				t.Errorf("%s: strict enables a setting reserved for future use", key)
+			}
+		case got.Kind() == reflect.Bool && !def.Bool() && !listSettings[key]:
+			if !got.Bool() {
+				t.Errorf("%s: opt-in setting not enabled by strict", key)
+			}
+		case got.Kind() == reflect.Int && strings.Contains(desc, "Set to 0 to disable") && def.Int() == 0:
+			if got.Int() <= 0 {
+				t.Errorf("%s: opt-in setting not enabled by strict", key)
+			}
+		}
+	}
+}
+
+func TestLoadExtendsFile(t *testing.T) {
+	dir := t.TempDir()
+	shared := filepath.Join(dir, "shared", "base.yml")
+	repo := filepath.Join(dir, "repo", "makefmt.yml")
+
+	writeConfig(t, shared, `extends: minimal
+formatter:
+  tab_width: 2
+  max_blank_lines: 1
+lint:
+  rules:
+    a: warn
+    b: warn
+`)
+	writeConfig(t, repo, `extends: ../shared/base.yml
+formatter:
+  max_blank_lines: 0
+lint:
+  rules:
+    b: error
+`)
+
+	cfg, err := Load(repo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Formatter.TabWidth != 2 {
+		t.Errorf("TabWidth: got %d, want 2 (from base)", cfg.Formatter.TabWidth)
+	}
+	if cfg.Formatter.MaxBlankLines != 0 {
+		t.Errorf("MaxBlankLines: got %d, want 0 (local override)", cfg.Formatter.MaxBlankLines)
+	}
+	if cfg.Formatter.AssignmentSpacing != "preserve" {
+		t.Errorf("AssignmentSpacing: got %q, want %q (from preset)", cfg.Formatter.AssignmentSpacing, "preserve")
+	}
+	if cfg.Lint.Rules["a"] != "warn" || cfg.Lint.Rules["b"] != "error" {
+		t.Errorf("Lint.Rules: got %v, want a=warn b=error (merged)", cfg.Lint.Rules)
+	}
+}
+
+func TestLoadExtendsErrors(t *testing.T) {
+	dir := t.TempDir()
+	a := filepath.Join(dir, "a.yml")
+	b := filepath.Join(dir, "b.yml")
+	writeConfig(t, a, "extends: b.yml\n")
+	writeConfig(t, b, "extends: a.yml\n")
+
+	if _, err := Load(a); err == nil {
+		t.Error("expected error for extends cycle")
+	}
+
+	missing := filepath.Join(dir, "missing.yml")
+	writeConfig(t, missing, "extends: nope.yml\n")
+	if _, err := Load(missing); err == nil {
+		t.Error("expected error for missing extends target")
+	}
+}
@@ -0,0 +1,387 @@
+package config
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// editorConfigName is the file name of EditorConfig files.
+const editorConfigName = ".editorconfig"
+
+// editorConfigFile is a parsed .editorconfig file.
+type editorConfigFile struct {
+	dir      string
+	root     bool
+	sections []editorConfigSection
+}
+
+// editorConfigSection is one [glob] section and its properties.
+type editorConfigSection struct {
+	pattern string
+	glob    *editorConfigGlob
+	props   map[string]string
+}
+
+// parseEditorConfig parses the contents of the .editorconfig file in dir.
+// Lines that cannot be parsed and sections with invalid globs are
+// ignored, as the EditorConfig specification requires.
+func parseEditorConfig(dir string, data []byte) *editorConfigFile {
+	ef := &editorConfigFile{dir: dir}
+	var section *editorConfigSection
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || line[0] == '#' || line[0] == ';' {
+			continue
+		}
+
+		if line[0] == '[' {
+			section = nil
+			end := strings.LastIndexByte(line, ']')
+			if end < 0 {
+				continue
+			}
+			glob, err := compileEditorConfigGlob(line[1:end])
+			if err != nil {
+				continue
+			}
+			ef.sections = append(ef.sections, editorConfigSection{
+				pattern: line[1:end],
+				glob:    glob,
+				props:   map[string]string{},
+			})
+			section = &ef.sections[len(ef.sections)-1]
+			continue
+		}
+
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		value = strings.ToLower(strings.TrimSpace(value))
+
+		switch {
+		case section != nil:
+			section.props[key] = value
+		case key == "root":
+			ef.root = value == "true"
+		}
+	}
+	return ef
+}
+
+// editorConfigProps returns the EditorConfig properties that apply to the
+// file at the absolute path. Files are read from the file's directory up
+// to the first .editorconfig with root = true, and properties from files
+// and sections closer to the file win. Parsed files are cached in cache
+// by directory. If prov is non-nil, it records the section that each
+// formatter setting derived from the properties came from.
+func editorConfigProps(path string, cache map[string]*editorConfigFile, prov Provenance) (map[string]string, error) {
+	var files []*editorConfigFile
+	for dir := filepath.Dir(path); ; {
+		ef, err := loadEditorConfig(dir, cache)
+		if err != nil {
+			return nil, err
+		}
+		if ef != nil {
+			files = append(files, ef)
+			if ef.root {
+				break
+			}
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			break
+		}
+		dir = parent
+	}
+
+	props := map[string]string{}
+	sources := map[string]string{}
+	for i := len(files) - 1; i >= 0; i-- {
+		ef := files[i]
+		rel, err := filepath.Rel(ef.dir, path)
+		if err != nil {
+			continue
+		}
+		rel = filepath.ToSlash(rel)
+		for _, s := range ef.sections {
+			if !s.glob.match(rel) {
+				continue
+			}
+			source := fmt.Sprintf("%s [%s]", filepath.Join(ef.dir, editorConfigName), s.pattern)
+			for k, v := range s.props {
+				props[k] = v
+				sources[k] = source
+			}
+		}
+	}
+
+	for k, v := range props {
+		if v == "unset" {
+			delete(props, k)
+		}
+	}
+
+	if prov != nil {
+		var f FormatterConfig
+		for setting, prop := range applyEditorConfig(&f, props) {
+			prov["formatter."+setting] = sources[prop]
+		}
+	}
+	return props, nil
+}
+
+// loadEditorConfig returns the parsed .editorconfig in dir, or nil if
+// there is none.
+func loadEditorConfig(dir string, cache map[string]*editorConfigFile) (*editorConfigFile, error) {
+	if ef, ok := cache[dir]; ok {
+		return ef, nil
+	}
+
+	var ef *editorConfigFile
+	path := filepath.Join(dir, editorConfigName)
+	data, err := os.ReadFile(path)
+	switch {
+	case err == nil:
+		ef = parseEditorConfig(dir, data)
+	case errors.Is(err, fs.ErrNotExist):
+	default:
+		return nil, fmt.Errorf("reading %s: %w", path, err)
+	}
+
+	cache[dir] = ef
+	return ef, nil
+}
+
+// applyEditorConfig maps EditorConfig properties onto f and returns the
+// property used for each formatter setting it changed, keyed by YAML name.
+// Properties with values makefmt cannot use are ignored; in particular
+// indent_style = space is ignored because recipes must be indented with
+// tabs.
+func applyEditorConfig(f *FormatterConfig, props map[string]string) map[string]string {
+	applied := map[string]string{}
+
+	if props["indent_style"] == "tab" {
+		f.IndentStyle = "tab"
+		applied["indent_style"] = "indent_style"
+	}
+
+	// tab_width defaults to indent_size when only the latter is set.
+	if n, ok := positiveProp(props, "tab_width"); ok {
+		f.TabWidth = n
+		applied["tab_width"] = "tab_width"
+	} else if n, ok := positiveProp(props, "indent_size"); ok {
+		f.TabWidth = n
+		applied["tab_width"] = "indent_size"
+	}
+
+	if b, err := strconv.ParseBool(props["trim_trailing_whitespace"]); err == nil {
+		f.TrimTrailingWhitespace = b
+		applied["trim_trailing_whitespace"] = "trim_trailing_whitespace"
+	}
+	if b, err := strconv.ParseBool(props["insert_final_newline"]); err == nil {
+		f.InsertFinalNewline = b
+		applied["insert_final_newline"] = "insert_final_newline"
+	}
+	return applied
+}
+
+func positiveProp(props map[string]string, key string) (int, bool) {
+	n, err := strconv.Atoi(props[key])
+	if err != nil || n <= 0 {
+		return 0, false
+	}
+	return n, true
+}
+
+// editorConfigGlob is a compiled EditorConfig section glob.
+type editorConfigGlob struct {
+	re     *regexp.Regexp
+	ranges [][2]int // Bounds of each {n1..n2} group, in order.
+}
+
+// match reports whether the slash-separated path, relative to the
+// directory of the .editorconfig file, matches the glob.
+func (g *editorConfigGlob) match(rel string) bool {
+	m := g.re.FindStringSubmatch(rel)
+	if m == nil {
+		return false
+	}
+	for i, r := range g.ranges {
+		if m[i+1] == "" {
+			continue // The group is in an alternative that did not match.
+		}
+		n, err := strconv.Atoi(m[i+1])
+		if err != nil || n < r[0] || n > r[1] {
+			return false
+		}
+	}
+	return true
+}
+
+// compileEditorConfigGlob compiles a section glob. A glob without a slash
+// matches file names at any depth; otherwise it is matched against the
+// path relative to the .editorconfig file.
+func compileEditorConfigGlob(glob string) (*editorConfigGlob, error) {
+	switch {
+	case strings.HasPrefix(glob, "/"):
+		glob = glob[1:]
+	case !strings.Contains(glob, "/"):
+		glob = "**/" + glob
+	}
+
+	g := &editorConfigGlob{}
+	expr, err := g.translate(glob)
+	if err != nil {
+		return nil, err
+	}
+	re, err := regexp.Compile("^" + expr + "$")
+	if err != nil {
+		return nil, err
+	}
+	g.re = re
+	return g, nil
+}
+
+var numericRange = regexp.MustCompile(`^([+-]?\d+)\.\.([+-]?\d+)$`)
+
+// translate converts glob syntax into a regular expression.
+func (g *editorConfigGlob) translate(glob string) (string, error) {
+	var b strings.Builder
+	for i := 0; i < len(glob); i++ {
+		c := glob[i]
+		switch c {
+		case '\\':
+			if i+1 < len(glob) {
+				i++
+				b.WriteString(regexp.QuoteMeta(glob[i : i+1]))
+			} else {
+				b.WriteString(`\\`)
+			}
+		case '*':
+			if i+1 < len(glob) && glob[i+1] == '*' {
+				i++
+				if i+1 < len(glob) && glob[i+1] == '/' {
+					i++
+					b.WriteString(`(?:.*/)?`)
+				} else {
+					b.WriteString(`.*`)
+				}
+			} else {
+				b.WriteString(`[^/]*`)
+			}
+		case '?':
+			b.WriteString(`[^/]`)
+		case '[':
+			end := strings.IndexByte(glob[i+1:], ']')
+			if end < 0 {
+				b.WriteString(`\[`)
+				continue
+			}
+			class := glob[i+1 : i+1+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			b.WriteString("[" + strings.ReplaceAll(class, `\`, `\\`) + "]")
+			i += end + 1
+		case '{':
+			end := matchingBrace(glob, i)
+			if end < 0 {
+				b.WriteString(`\{`)
+				continue
+			}
+			expr, err := g.translateBraces(glob[i+1 : end])
+			if err != nil {
+				return "", err
+			}
+			b.WriteString(expr)
+			i = end
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	return b.String(), nil
+}
+
+// translateBraces converts the contents of a {...} group.
+func (g *editorConfigGlob) translateBraces(inner string) (string, error) {
+	if m := numericRange.FindStringSubmatch(inner); m != nil {
+		lo, _ := strconv.Atoi(m[1])
+		hi, _ := strconv.Atoi(m[2])
+		g.ranges = append(g.ranges, [2]int{min(lo, hi), max(lo, hi)})
+		return `([+-]?\d+)`, nil
+	}
+
+	alts := splitAlternatives(inner)
+	if len(alts) == 1 {
+		// A single alternative is matched literally, braces included.
+		expr, err := g.translate(inner)
+		if err != nil {
+			return "", err
+		}
+		return `\{` + expr + `\}`, nil
+	}
+
+	parts := make([]string, len(alts))
+	for i, alt := range alts {
+		expr, err := g.translate(alt)
+		if err != nil {
+			return "", err
+		}
+		parts[i] = expr
+	}
+	return "(?:" + strings.Join(parts, "|") + ")", nil
+}
+
+// matchingBrace returns the index of the } closing the { at open, or -1.
+func matchingBrace(s string, open int) int {
+	depth := 0
+	for i := open; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// splitAlternatives splits s at commas that are not nested in braces.
+func splitAlternatives(s string) []string {
+	var alts []string
+	depth, start := 0, 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '{':
+			depth++
+		case '}':
+			depth--
+		case ',':
+			if depth == 0 {
+				alts = append(alts, s[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(alts, s[start:])
+}
@@ -0,0 +1,170 @@
+package config
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestEditorConfigGlob(t *testing.T) {
+	tests := []struct {
+		glob string
+		path string
+		want bool
+	}{
+		{"*", "Makefile", true},
+		{"*", "a/b/Makefile", true},
+		{"Makefile", "Makefile", true},
+		{"Makefile", "sub/Makefile", true},
+		{"Makefile", "GNUmakefile", false},
+		{"*.mk", "rules.mk", true},
+		{"*.mk", "a/rules.mk", true},
+		{"*.{mk,make}", "a/rules.make", true},
+		{"*.{mk,make}", "a/rules.txt", false},
+		{"{Makefile,*.mk}", "x/Makefile", true},
+		{"/Makefile", "Makefile", true},
+		{"/Makefile", "sub/Makefile", false},
+		{"build/*.mk", "build/a.mk", true},
+		{"build/*.mk", "build/x/a.mk", false},
+		{"build/**.mk", "build/x/a.mk", true},
+		{"src/**/Makefile", "src/Makefile", true},
+		{"src/**/Makefile", "src/a/b/Makefile", true},
+		{"file?.mk", "file1.mk", true},
+		{"file?.mk", "file12.mk", false},
+		{"file[0-9].mk", "file5.mk", true},
+		{"file[!0-9].mk", "file5.mk", false},
+		{"file{1..3}.mk", "file2.mk", true},
+		{"file{1..3}.mk", "file4.mk", false},
+		{"{single}", "{single}", true},
+		{`\*.mk`, "*.mk", true},
+		{`\*.mk`, "a.mk", false},
+	}
+
+	for _, tt := range tests {
+		g, err := compileEditorConfigGlob(tt.glob)
+		if err != nil {
+			t.Fatalf("compile %q: %v", tt.glob, err)
+		}
+		if got := g.match(tt.path); got != tt.want {
+			t.Errorf("glob %q match %q = %v, want %v", tt.glob, tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestEditorConfigCascade(t *testing.T) {
+	outer := t.TempDir()
+	repo := filepath.Join(outer, "repo")
+	sub := filepath.Join(repo, "sub")
+
+	// Ignored: the repository's .editorconfig sets root = true.
+	writeConfig(t, filepath.Join(outer, ".editorconfig"), "[*]\ninsert_final_newline = false\n")
+	writeConfig(t, filepath.Join(repo, ".editorconfig"), `root = true
+
+[*]
+indent_style = space
+indent_size = 2
+trim_trailing_whitespace = true
+
+[{Makefile,*.mk}]
+indent_style = tab
+tab_width = 8
+`)
+	writeConfig(t, filepath.Join(sub, ".editorconfig"), `; Nested file without root.
+[*.mk]
+tab_width = 3
+trim_trailing_whitespace = unset
+`)
+
+	cache := map[string]*editorConfigFile{}
+	tests := []struct {
+		path string
+		want map[string]string
+	}{
+		{
+			filepath.Join(repo, "README"),
+			map[string]string{"indent_style": "space", "indent_size": "2", "trim_trailing_whitespace": "true"},
+		},
+		{
+			filepath.Join(repo, "Makefile"),
+			map[string]string{"indent_style": "tab", "indent_size": "2", "tab_width": "8", "trim_trailing_whitespace": "true"},
+		},
+		{
+			filepath.Join(sub, "rules.mk"),
+			map[string]string{"indent_style": "tab", "indent_size": "2", "tab_width": "3"},
+		},
+	}
+
+	for _, tt := range tests {
+		got, err := editorConfigProps(tt.path, cache, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(got) != len(tt.want) {
+			t.Errorf("%s: got %v, want %v", tt.path, got, tt.want)
+			continue
+		}
+		for k, v := range tt.want {
+			if got[k] != v {
+				t.Errorf("%s: %s got %q, want %q", tt.path, k, got[k], v)
+			}
+		}
+	}
+}
+
+func TestApplyEditorConfig(t *testing.T) {
+	tests := []struct {
+		name  string
+		props map[string]string
+		check func(f FormatterConfig) bool
+	}{
+		{"tab_width", map[string]string{"tab_width": "8", "indent_size": "2"}, func(f FormatterConfig) bool { return f.TabWidth == 8 }},
+		{"indent_size fallback", map[string]string{"indent_size": "2"}, func(f FormatterConfig) bool { return f.TabWidth == 2 }},
+		{"indent_size tab", map[string]string{"indent_size": "tab"}, func(f FormatterConfig) bool { return f.TabWidth == 4 }},
+		{"space indent ignored", map[string]string{"indent_style": "space"}, func(f FormatterConfig) bool { return f.IndentStyle == "tab" }},
+		{"trim", map[string]string{"trim_trailing_whitespace": "false"}, func(f FormatterConfig) bool { return !f.TrimTrailingWhitespace }},
+		{"final newline", map[string]string{"insert_final_newline": "false"}, func(f FormatterConfig) bool { return !f.InsertFinalNewline }},
+		{"invalid value", map[string]string{"tab_width": "wide"}, func(f FormatterConfig) bool { return f.TabWidth == 4 }},
+	}
+
+	for _, tt := range tests {
+		f := DefaultConfig().Formatter
+		applyEditorConfig(&f, tt.props)
+		if !tt.check(f) {
+			t.Errorf("%s: unexpected config %+v", tt.name, f)
+		}
+	}
+}
+
+func TestResolverEditorConfigPrecedence(t *testing.T) {
+	repo := t.TempDir()
+	writeConfig(t, filepath.Join(repo, ".git", "HEAD"), "")
+	writeConfig(t, filepath.Join(repo, ".editorconfig"), `root = true
+
+[Makefile]
+tab_width = 8
+insert_final_newline = false
+
+[*.mk]
+tab_width = 2
+`)
+	writeConfig(t, filepath.Join(repo, "makefmt.yml"), "formatter:\n  insert_final_newline: true\n")
+
+	r := NewResolver("", nil)
+	mk, err := r.ForFile(filepath.Join(repo, "Makefile"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mk.Formatter.TabWidth != 8 {
+		t.Errorf("Makefile TabWidth: got %d, want 8 (from .editorconfig)", mk.Formatter.TabWidth)
+	}
+	if !mk.Formatter.InsertFinalNewline {
+		t.Error("Makefile InsertFinalNewline: got false, want true (makefmt.yml wins)")
+	}
+
+	inc, err := r.ForFile(filepath.Join(repo, "rules.mk"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if inc.Formatter.TabWidth != 2 {
+		t.Errorf("rules.mk TabWidth: got %d, want 2 (from .editorconfig)", inc.Formatter.TabWidth)
+	}
+}
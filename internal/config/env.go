@@ -0,0 +1,67 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// EnvConfigPath is the environment variable that names the config file
+// when --config is not given.
+const EnvConfigPath = "MAKEFMT_CONFIG"
+
+// envPrefix starts the environment variables that set formatter
+// settings, such as MAKEFMT_MAX_BLANK_LINES.
+const envPrefix = "MAKEFMT_"
+
+// EnvSettings returns a Setting for each MAKEFMT_<FIELD> variable in
+// environ, a list of key=value strings as returned by os.Environ. FIELD is
+// the upper-case YAML name of a formatter setting. Settings are returned
+// sorted by variable name; values are checked when they are applied.
+//
+// Other MAKEFMT_ variables are ignored, since they may belong to scripts
+// or other tools. A warning is returned for each one that looks like a
+// misspelled setting.
+func EnvSettings(environ []string) (settings []Setting, warnings []string) {
+	fields := map[string]string{}
+	for name := range yamlFields(reflect.TypeFor[FormatterConfig]()) {
+		fields[strings.ToUpper(name)] = name
+	}
+
+	for _, kv := range environ {
+		key, value, _ := strings.Cut(kv, "=")
+		if !strings.HasPrefix(key, envPrefix) || key == EnvConfigPath {
+			continue
+		}
+
+		name, ok := fields[strings.TrimPrefix(key, envPrefix)]
+		if !ok {
+			if w := misspelledEnv(key, fields); w != "" {
+				warnings = append(warnings, w)
+			}
+			continue
+		}
+		settings = append(settings, Setting{Key: "formatter." + name, Value: value, Source: key})
+	}
+
+	slices.SortFunc(settings, func(a, b Setting) int { return strings.Compare(a.Source, b.Source) })
+	slices.Sort(warnings)
+	return settings, warnings
+}
+
+// misspelledEnv returns a warning if key, an unknown MAKEFMT_ variable,
+// is close to the name of a setting, and an empty string otherwise.
+func misspelledEnv(key string, fields map[string]string) string {
+	names := make([]string, 0, len(fields)+1)
+	for name := range fields {
+		names = append(names, name)
+	}
+	names = append(names, strings.TrimPrefix(EnvConfigPath, envPrefix))
+
+	s := Suggest(strings.TrimPrefix(key, envPrefix), names)
+	if s == "" {
+		return ""
+	}
+	return fmt.Sprintf("ignoring unknown environment variable %s (did you mean %s?)", key, envPrefix+s)
+}
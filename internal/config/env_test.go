@@ -0,0 +1,72 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestEnvSettings(t *testing.T) {
+	settings, warnings := EnvSettings([]string{
+		"HOME=/root",
+		"MAKEFMT_CONFIG=/etc/makefmt.yml",
+		"MAKEFMT_TAB_WIDTH=8",
+		"MAKEFMT_INDENT_CONDITIONALS=false",
+	})
+	if len(warnings) != 0 {
+		t.Errorf("unexpected warnings: %v", warnings)
+	}
+
+	want := []Setting{
+		{Key: "formatter.indent_conditionals", Value: "false", Source: "MAKEFMT_INDENT_CONDITIONALS"},
+		{Key: "formatter.tab_width", Value: "8", Source: "MAKEFMT_TAB_WIDTH"},
+	}
+	if len(settings) != len(want) {
+		t.Fatalf("EnvSettings = %v, want %v", settings, want)
+	}
+	for i := range want {
+		if settings[i] != want[i] {
+			t.Errorf("setting %d: got %+v, want %+v", i, settings[i], want[i])
+		}
+	}
+}
+
+func TestEnvSettingsUnknown(t *testing.T) {
+	settings, warnings := EnvSettings([]string{"MAKEFMT_TAB_WIDHT=8", "MAKEFMT_VERSION=1", "MAKEFMT_MAX_BLANK_LINES=1"})
+	if len(settings) != 1 || settings[0].Source != "MAKEFMT_MAX_BLANK_LINES" {
+		t.Errorf("settings: got %+v, want only MAKEFMT_MAX_BLANK_LINES", settings)
+	}
+
+	// Unrelated variables are ignored silently; likely typos are warned about.
+	want := "ignoring unknown environment variable MAKEFMT_TAB_WIDHT (did you mean MAKEFMT_TAB_WIDTH?)"
+	if len(warnings) != 1 || warnings[0] != want {
+		t.Errorf("warnings: got %q, want [%q]", warnings, want)
+	}
+}
+
+func TestEnvSettingsPrecedence(t *testing.T) {
+	env, _ := EnvSettings([]string{"MAKEFMT_TAB_WIDTH=8", "MAKEFMT_MAX_BLANK_LINES=1"})
+
+	// Flags are applied after the environment.
+	r := NewResolver("", nil)
+	if err := r.Set(append(env, Setting{Key: "tab_width", Value: "2", Source: "--set"})...); err != nil {
+		t.Fatal(err)
+	}
+	cfg, prov, err := r.Explain(t.TempDir() + "/Makefile")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if cfg.Formatter.TabWidth != 2 || prov["formatter.tab_width"] != "flag --set" {
+		t.Errorf("tab_width: got %d from %q, want 2 from flag --set", cfg.Formatter.TabWidth, prov["formatter.tab_width"])
+	}
+	if cfg.Formatter.MaxBlankLines != 1 || prov["formatter.max_blank_lines"] != "env MAKEFMT_MAX_BLANK_LINES" {
+		t.Errorf("max_blank_lines: got %d from %q, want 1 from env", cfg.Formatter.MaxBlankLines, prov["formatter.max_blank_lines"])
+	}
+
+	// Invalid values are reported with the variable name.
+	bad, _ := EnvSettings([]string{"MAKEFMT_TAB_WIDTH=wide"})
+	err = NewResolver("", nil).Set(bad...)
+	if err == nil || !strings.HasPrefix(err.Error(), "MAKEFMT_TAB_WIDTH=wide: ") {
+		t.Errorf("got %v, want error for MAKEFMT_TAB_WIDTH=wide", err)
+	}
+}
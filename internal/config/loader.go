@@ -4,8 +4,11 @@ import (
 	"errors"
 	"fmt"
 	"io/fs"
+	"maps"
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
 
 	"gopkg.in/yaml.v3"
 )
@@ -32,9 +35,8 @@ func Discover(dir string) string {
 }
 
 // Load reads and parses a makefmt config file. If configPath is non-empty,
-// that file is loaded directly. Otherwise, Load searches the current working
-// directory using Discover. If no config file is found, DefaultConfig is
-// returned.
+// that file is loaded directly. Otherwise, Load returns the config that
+// applies to the current working directory (see LoadDir).
 //
 // Partial YAML files are supported: any fields not specified in the YAML
 // retain their default values.
@@ -44,32 +46,381 @@ func Load(configPath string) (*Config, error) {
 		if err != nil {
 			return nil, fmt.Errorf("getting working directory: %w", err)
 		}
-		configPath = Discover(wd)
+		return LoadDir(wd)
 	}
 
-	if configPath == "" {
-		return DefaultConfig(), nil
+	// Start from defaults so missing YAML fields retain non-zero defaults.
+	return layer(DefaultConfig(), []string{configPath}, nil, nil)
+}
+
+// LoadFile reads and parses a config from the given path. Unlike Load, it
+// does not perform discovery — the path must be provided.
+func LoadFile(path string) (*Config, error) {
+	return Load(path)
+}
+
+// LoadDir returns the config that applies to files in dir. Config files
+// found by DiscoverChain are layered onto DefaultConfig from the outermost
+// directory inwards, so a nested config only needs to list the settings
+// it changes. If no config file is found, DefaultConfig is returned.
+func LoadDir(dir string) (*Config, error) {
+	paths, err := DiscoverChain(dir)
+	if err != nil {
+		return nil, err
+	}
+	return layer(DefaultConfig(), paths, nil, nil)
+}
+
+// LoadForFile returns the config that applies to the file at path: the
+// defaults, then any .editorconfig settings for the file, then the config
+// files found by DiscoverChain, then matching overrides.
+func LoadForFile(path string) (*Config, error) {
+	return NewResolver("", nil).ForFile(path)
+}
+
+// layer loads the config files at paths onto cfg in order. If prov is
+// non-nil, it records the file each setting came from. If rules is
+// non-nil, formatter.rules entries are checked against it.
+func layer(cfg *Config, paths []string, prov Provenance, rules []string) (*Config, error) {
+	for _, path := range paths {
+		l := &loader{seen: map[string]bool{}, prov: prov, rules: rules}
+		if err := l.load(cfg, path); err != nil {
+			return nil, err
+		}
+	}
+
+	// Root only describes the file it appears in.
+	cfg.Root = false
+	return cfg, nil
+}
+
+// loader layers config files, and the presets and files they extend,
+// onto a Config.
+type loader struct {
+	seen  map[string]bool // Files being loaded, to reject extends cycles.
+	prov  Provenance      // Source of each setting; nil if not tracked.
+	rules []string        // Allowed formatter.rules keys; nil allows any.
+}
+
+// load layers the config file at path onto cfg. If the file sets extends,
+// the preset or file it names is layered first, so the file's own
+// settings win.
+func (l *loader) load(cfg *Config, path string) error {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return fmt.Errorf("resolving %s: %w", path, err)
+	}
+	if l.seen[abs] {
+		return fmt.Errorf("config file %s extends itself", path)
+	}
+	l.seen[abs] = true
+	defer delete(l.seen, abs)
+
+	data, err := readConfigFile(path)
+	if err != nil {
+		return err
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return fmt.Errorf("parsing config file %s: %w", path, err)
+	}
+	if err := validateDocument(path, &doc, l.rules); err != nil {
+		return err
+	}
+
+	var header struct {
+		Extends string `yaml:"extends"`
+	}
+	if err := decodeDocument(&doc, &header); err != nil {
+		return fmt.Errorf("parsing config file %s: %w", path, err)
+	}
+	if header.Extends != "" {
+		if err := l.extend(cfg, header.Extends, filepath.Dir(abs)); err != nil {
+			return fmt.Errorf("config file %s: %w", path, err)
+		}
+	}
+
+	inherited := cfg.Overrides
+	cfg.Overrides = nil
+	if err := decodeDocument(&doc, cfg); err != nil {
+		return fmt.Errorf("parsing config file %s: %w", path, err)
+	}
+	if err := checkOverrides(cfg.Overrides, path); err != nil {
+		return fmt.Errorf("config file %s: %w", path, err)
+	}
+	cfg.Overrides = append(inherited, cfg.Overrides...)
+	l.prov.record(&doc, path)
+
+	// Extends only describes the file it appears in.
+	cfg.Extends = ""
+	return nil
+}
+
+// decodeDocument decodes a parsed config file into out. An empty file
+// leaves out unchanged.
+func decodeDocument(doc *yaml.Node, out any) error {
+	if doc.Kind == 0 {
+		return nil
+	}
+	return doc.Decode(out)
+}
+
+// extend layers the preset or config file named by ref onto cfg. Relative
+// paths are resolved against dir, the directory of the extending file.
+// A file whose name matches a preset can be referenced as ./name.
+func (l *loader) extend(cfg *Config, ref, dir string) error {
+	if preset, ok := presets[ref]; ok {
+		var doc yaml.Node
+		if err := yaml.Unmarshal([]byte(preset), &doc); err != nil {
+			return fmt.Errorf("parsing preset %s: %w", ref, err)
+		}
+		if err := doc.Decode(cfg); err != nil {
+			return fmt.Errorf("parsing preset %s: %w", ref, err)
+		}
+		l.prov.record(&doc, "preset "+ref)
+		return nil
+	}
+
+	if !filepath.IsAbs(ref) {
+		ref = filepath.Join(dir, ref)
+	}
+	return l.load(cfg, ref)
+}
+
+// DiscoverChain returns the config files that apply to dir, ordered from
+// the outermost directory to dir itself. Starting at dir, it walks up the
+// directory tree and stops after a directory that contains .git (the
+// repository root), after a config file that sets root: true, or at the
+// file system root.
+func DiscoverChain(dir string) ([]string, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, fmt.Errorf("resolving %s: %w", dir, err)
+	}
+
+	var chain []string
+	for {
+		if path := Discover(dir); path != "" {
+			chain = append(chain, path)
+
+			root, err := isRootConfig(path)
+			if err != nil {
+				return nil, err
+			}
+			if root {
+				break
+			}
+		}
+
+		if isRepoRoot(dir) {
+			break
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			break
+		}
+		dir = parent
+	}
+
+	slices.Reverse(chain)
+	return chain, nil
+}
+
+// isRootConfig reports whether the config file at path sets root: true.
+func isRootConfig(path string) (bool, error) {
+	data, err := readConfigFile(path)
+	if err != nil {
+		return false, err
+	}
+
+	var marker struct {
+		Root bool `yaml:"root"`
 	}
+	if err := yaml.Unmarshal(data, &marker); err != nil {
+		return false, fmt.Errorf("parsing config file %s: %w", path, err)
+	}
+	return marker.Root, nil
+}
 
-	data, err := os.ReadFile(configPath)
+// isRepoRoot reports whether dir contains a .git directory or file.
+func isRepoRoot(dir string) bool {
+	_, err := os.Stat(filepath.Join(dir, ".git"))
+	return err == nil
+}
+
+func readConfigFile(path string) ([]byte, error) {
+	data, err := os.ReadFile(path)
 	if err != nil {
 		if errors.Is(err, fs.ErrNotExist) {
-			return nil, fmt.Errorf("config file not found: %s", configPath)
+			return nil, fmt.Errorf("config file not found: %s", path)
 		}
-		return nil, fmt.Errorf("reading config file %s: %w", configPath, err)
+		return nil, fmt.Errorf("reading config file %s: %w", path, err)
 	}
+	return data, nil
+}
 
-	// Start from defaults so missing YAML fields retain non-zero defaults.
-	cfg := DefaultConfig()
-	if err := yaml.Unmarshal(data, cfg); err != nil {
-		return nil, fmt.Errorf("parsing config file %s: %w", configPath, err)
+// Resolver returns the config for each file in a run, caching what it
+// reads so that files in different subtrees of a repository can use
+// different configs within one invocation.
+type Resolver struct {
+	explicitPath string
+	ruleNames    []string
+	flags        flagSettings
+
+	chains        map[string][]string          // Config files by directory.
+	editorConfigs map[string]*editorConfigFile // .editorconfig by directory.
+	configs       map[string]*Config           // Loaded configs by configKey.
+}
+
+// NewResolver returns a Resolver. If explicitPath is non-empty, that
+// config file is used for every file instead of discovery. ruleNames are
+// the formatting rules that formatter.rules may turn on or off; entries
+// naming any other rule are rejected. A nil ruleNames accepts any name.
+func NewResolver(explicitPath string, ruleNames []string) *Resolver {
+	return &Resolver{
+		explicitPath:  explicitPath,
+		ruleNames:     ruleNames,
+		chains:        make(map[string][]string),
+		editorConfigs: make(map[string]*editorConfigFile),
+		configs:       make(map[string]*Config),
+	}
+}
+
+// Set adds settings that are applied on top of every config the Resolver
+// returns, in order. It reports an error if a setting is unknown or has
+// an invalid value.
+func (r *Resolver) Set(settings ...Setting) error {
+	for _, s := range settings {
+		if err := r.flags.add(s, r.ruleNames); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ForFile returns the config that applies to the file at path, including
+// .editorconfig settings for the file, matching overrides, and settings
+// added with Set.
+func (r *Resolver) ForFile(path string) (*Config, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return nil, fmt.Errorf("resolving %s: %w", path, err)
+	}
+	props, err := editorConfigProps(abs, r.editorConfigs, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg, err := r.load(filepath.Dir(abs), props)
+	if err != nil {
+		return nil, err
+	}
+	if cfg, err = cfg.forFile(abs, nil); err != nil {
+		return nil, err
+	}
+	return r.flags.apply(cfg, nil)
+}
+
+// ForDir returns the config that applies to files in dir, including
+// settings added with Set. Neither .editorconfig settings nor overrides
+// are applied, since both depend on the file name.
+func (r *Resolver) ForDir(dir string) (*Config, error) {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, fmt.Errorf("resolving %s: %w", dir, err)
+	}
+	cfg, err := r.load(abs, nil)
+	if err != nil {
+		return nil, err
+	}
+	return r.flags.apply(cfg, nil)
+}
+
+// Explain returns the config that applies to the file at path, like
+// ForFile, together with the source of each formatter and lint setting.
+// Results are not cached.
+func (r *Resolver) Explain(path string) (*Config, Provenance, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("resolving %s: %w", path, err)
+	}
+	prov := defaultProvenance()
+	props, err := editorConfigProps(abs, r.editorConfigs, prov)
+	if err != nil {
+		return nil, nil, err
 	}
 
+	paths, err := r.paths(filepath.Dir(abs))
+	if err != nil {
+		return nil, nil, err
+	}
+	base := DefaultConfig()
+	applyEditorConfig(&base.Formatter, props)
+	cfg, err := layer(base, paths, prov, r.ruleNames)
+	if err != nil {
+		return nil, nil, err
+	}
+	if cfg, err = cfg.forFile(abs, prov); err != nil {
+		return nil, nil, err
+	}
+	if cfg, err = r.flags.apply(cfg, prov); err != nil {
+		return nil, nil, err
+	}
+	return cfg, prov, nil
+}
+
+// load returns the config files for dir layered onto the defaults with
+// the given .editorconfig properties applied.
+func (r *Resolver) load(dir string, props map[string]string) (*Config, error) {
+	paths, err := r.paths(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	key := configKey(paths, props)
+	if cfg, ok := r.configs[key]; ok {
+		return cfg, nil
+	}
+
+	base := DefaultConfig()
+	applyEditorConfig(&base.Formatter, props)
+	cfg, err := layer(base, paths, nil, r.ruleNames)
+	if err != nil {
+		return nil, err
+	}
+	r.configs[key] = cfg
 	return cfg, nil
 }
 
-// LoadFile reads and parses a config from the given path. Unlike Load, it
-// does not perform discovery — the path must be provided.
-func LoadFile(path string) (*Config, error) {
-	return Load(path)
+// paths returns the config files to load for files in dir: the explicit
+// config, or the files found by DiscoverChain.
+func (r *Resolver) paths(dir string) ([]string, error) {
+	if r.explicitPath != "" {
+		return []string{r.explicitPath}, nil
+	}
+	if paths, ok := r.chains[dir]; ok {
+		return paths, nil
+	}
+
+	paths, err := DiscoverChain(dir)
+	if err != nil {
+		return nil, err
+	}
+	r.chains[dir] = paths
+	return paths, nil
+}
+
+// configKey identifies a config by the files it is loaded from and the
+// .editorconfig properties it starts from.
+func configKey(paths []string, props map[string]string) string {
+	keys := slices.Sorted(maps.Keys(props))
+	var b strings.Builder
+	for _, p := range paths {
+		b.WriteString(p + "\x00")
+	}
+	for _, k := range keys {
+		b.WriteString("\x01" + k + "=" + props[k])
+	}
+	return b.String()
 }
@@ -0,0 +1,127 @@
+package config
+
+import (
+	"fmt"
+	"maps"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Override applies partial formatter and lint settings on top of the
+// base config for files that match any of its glob patterns.
+type Override struct {
+	// Files are glob patterns relative to the directory of the config
+	// file that declares the override.
+	Files []string `yaml:"files"`
+
+	Formatter yaml.Node `yaml:"formatter"`
+	Lint      yaml.Node `yaml:"lint"`
+
+	dir    string // Directory that Files are relative to.
+	source string // Path of the declaring config file.
+}
+
+// ForFile returns the config for the file at path: c with every matching
+// override applied in the order they were declared. If no override
+// matches, c itself is returned.
+func (c *Config) ForFile(path string) (*Config, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return nil, fmt.Errorf("resolving %s: %w", path, err)
+	}
+	return c.forFile(abs, nil)
+}
+
+// forFile is ForFile for an absolute path. If prov is non-nil, it records
+// the override each setting came from.
+func (c *Config) forFile(abs string, prov Provenance) (*Config, error) {
+	result := c
+	for i := range c.Overrides {
+		o := &c.Overrides[i]
+		if !o.matches(abs) {
+			continue
+		}
+		if result == c {
+			result = c.clone()
+		}
+		if err := o.apply(result); err != nil {
+			return nil, err
+		}
+		prov.recordOverride(o)
+	}
+	return result, nil
+}
+
+// matches reports whether any of the override's patterns match the
+// absolute path.
+func (o *Override) matches(abs string) bool {
+	rel, err := filepath.Rel(o.dir, abs)
+	if err != nil {
+		return false
+	}
+	rel = filepath.ToSlash(rel)
+	if rel == ".." || strings.HasPrefix(rel, "../") {
+		return false
+	}
+
+	for _, pattern := range o.Files {
+		if matchGlob(pattern, rel) {
+			return true
+		}
+	}
+	return false
+}
+
+// apply decodes the override's settings onto cfg.
+func (o *Override) apply(cfg *Config) error {
+	if o.Formatter.Kind != 0 {
+		if err := o.Formatter.Decode(&cfg.Formatter); err != nil {
+			return fmt.Errorf("override %v: formatter: %w", o.Files, err)
+		}
+	}
+	if o.Lint.Kind != 0 {
+		if err := o.Lint.Decode(&cfg.Lint); err != nil {
+			return fmt.Errorf("override %v: lint: %w", o.Files, err)
+		}
+	}
+	return nil
+}
+
+// clone returns a copy of c that can be modified without affecting c.
+func (c *Config) clone() *Config {
+	out := *c
+	out.Formatter.Rules = maps.Clone(c.Formatter.Rules)
+	out.Lint.Rules = maps.Clone(c.Lint.Rules)
+	out.Lint.Exclude = slices.Clone(c.Lint.Exclude)
+	out.Overrides = slices.Clone(c.Overrides)
+	return &out
+}
+
+// checkOverrides validates the overrides declared by the config file at
+// path and records the file's directory as the base for their patterns.
+func checkOverrides(overrides []Override, path string) error {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return fmt.Errorf("resolving %s: %w", path, err)
+	}
+	for i := range overrides {
+		o := &overrides[i]
+		if len(o.Files) == 0 {
+			return fmt.Errorf("overrides[%d]: files must not be empty", i)
+		}
+		for _, pattern := range o.Files {
+			if _, err := filepath.Match(pattern, ""); err != nil {
+				return fmt.Errorf("overrides[%d]: invalid pattern %q: %w", i, pattern, err)
+			}
+		}
+		if err := o.apply(DefaultConfig()); err != nil {
+			return fmt.Errorf("overrides[%d]: %w", i, err)
+		}
+		o.dir = filepath.Dir(abs)
+		o.source = path
+	}
+	return nil
+}
@@ -0,0 +1,143 @@
+package config
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestMatchGlob(t *testing.T) {
+	tests := []struct {
+		pattern string
+		name    string
+		want    bool
+	}{
+		{"*.mk", "rules.mk", true},
+		{"*.mk", "a/b/rules.mk", true},
+		{"*.mk", "Makefile", false},
+		{"**/*.mk", "rules.mk", true},
+		{"**/*.mk", "a/b/rules.mk", true},
+		{"third_party/**", "third_party/Makefile", true},
+		{"third_party/**", "third_party/a/b/x.mk", true},
+		{"third_party/**", "src/third_party/Makefile", false},
+		{"./build/Makefile", "build/Makefile", true},
+		{"build/*/Makefile", "build/x/Makefile", true},
+		{"build/*/Makefile", "build/x/y/Makefile", false},
+		{"a/**/b/Makefile", "a/b/Makefile", true},
+		{"a/**/b/Makefile", "a/x/y/b/Makefile", true},
+	}
+
+	for _, tt := range tests {
+		if got := matchGlob(tt.pattern, tt.name); got != tt.want {
+			t.Errorf("matchGlob(%q, %q) = %v, want %v", tt.pattern, tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestConfigForFileOverrides(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "makefmt.yml")
+	writeConfig(t, path, `formatter:
+  max_blank_lines: 1
+overrides:
+  - files: ["third_party/**"]
+    formatter:
+      assignment_spacing: preserve
+      indent_conditionals: false
+    lint:
+      rules:
+        x: off
+  - files: ["*.mk"]
+    formatter:
+      tab_width: 2
+`)
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		file              string
+		assignmentSpacing string
+		indent            bool
+		tabWidth          int
+	}{
+		{"Makefile", "space", true, 4},
+		{"lib/rules.mk", "space", true, 2},
+		{"third_party/Makefile", "preserve", false, 4},
+		{"third_party/x/rules.mk", "preserve", false, 2},
+	}
+
+	for _, tt := range tests {
+		got, err := cfg.ForFile(filepath.Join(dir, tt.file))
+		if err != nil {
+			t.Fatalf("ForFile(%s): %v", tt.file, err)
+		}
+		f := got.Formatter
+		if f.AssignmentSpacing != tt.assignmentSpacing || f.IndentConditionals != tt.indent || f.TabWidth != tt.tabWidth {
+			t.Errorf("%s: got spacing=%q indent=%v tab_width=%d, want %q %v %d",
+				tt.file, f.AssignmentSpacing, f.IndentConditionals, f.TabWidth,
+				tt.assignmentSpacing, tt.indent, tt.tabWidth)
+		}
+		if f.MaxBlankLines != 1 {
+			t.Errorf("%s: MaxBlankLines got %d, want 1 (base)", tt.file, f.MaxBlankLines)
+		}
+	}
+
+	// Applying an override must not modify the base config.
+	if cfg.Formatter.AssignmentSpacing != "space" || cfg.Lint.Rules != nil {
+		t.Errorf("base config modified: %+v", cfg)
+	}
+}
+
+func TestOverridesRelativeToDeclaringFile(t *testing.T) {
+	repo := t.TempDir()
+	sub := filepath.Join(repo, "sub")
+	writeConfig(t, filepath.Join(repo, ".git", "HEAD"), "")
+	writeConfig(t, filepath.Join(repo, "makefmt.yml"), `overrides:
+  - files: ["gen/*"]
+    formatter:
+      tab_width: 2
+`)
+	writeConfig(t, filepath.Join(sub, "makefmt.yml"), `overrides:
+  - files: ["gen/*"]
+    formatter:
+      tab_width: 8
+`)
+
+	r := NewResolver("", nil)
+	tests := []struct {
+		file string
+		want int
+	}{
+		{filepath.Join(repo, "gen", "Makefile"), 2},
+		{filepath.Join(sub, "gen", "Makefile"), 8},
+		{filepath.Join(sub, "Makefile"), 4},
+	}
+	for _, tt := range tests {
+		cfg, err := r.ForFile(tt.file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if cfg.Formatter.TabWidth != tt.want {
+			t.Errorf("%s: TabWidth got %d, want %d", tt.file, cfg.Formatter.TabWidth, tt.want)
+		}
+	}
+}
+
+func TestOverridesInvalid(t *testing.T) {
+	tests := map[string]string{
+		"no files":    "overrides:\n  - formatter:\n      tab_width: 2\n",
+		"bad pattern": "overrides:\n  - files: [\"[\"]\n",
+		"bad value":   "overrides:\n  - files: [\"*\"]\n    formatter:\n      tab_width: wide\n",
+	}
+	for name, content := range tests {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "makefmt.yml")
+			writeConfig(t, path, content)
+			if _, err := Load(path); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+}
@@ -0,0 +1,57 @@
+package config
+
+import "slices"
+
+// presets are the built-in configs that a config file can name in
+// extends. Each is layered onto DefaultConfig like a config file.
+var presets = map[string]string{
+	// gnu follows the conventions of the GNU Make manual: tab stops every
+	// eight columns and unindented conditionals.
+	"gnu": `
+formatter:
+  tab_width: 8
+  max_blank_lines: 1
+  assignment_spacing: space
+  indent_conditionals: false
+`,
+
+	// minimal only normalizes whitespace and leaves the rest of the file
+	// as written.
+	"minimal": `
+formatter:
+  trim_trailing_whitespace: true
+  insert_final_newline: true
+  assignment_spacing: preserve
+  align_backslash_continuations: false
+  space_after_comment: false
+  indent_conditionals: false
+`,
+
+	// strict turns on every opt-in formatter setting. The list settings
+	// are left out because they need a project's own variable names, as
+	// are the settings reserved for future use.
+	"strict": `
+formatter:
+  max_blank_lines: 1
+  insert_final_newline: true
+  trim_trailing_whitespace: true
+  assignment_spacing: space
+  align_backslash_continuations: true
+  space_after_comment: true
+  indent_conditionals: true
+  expand_inline_recipes: true
+  align_inline_comments: true
+  max_line_length: 80
+  indent_continuations: true
+`,
+}
+
+// Presets returns the names of the built-in presets in sorted order.
+func Presets() []string {
+	names := make([]string, 0, len(presets))
+	for name := range presets {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
@@ -0,0 +1,121 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// SourceDefault is the provenance of settings that keep their default.
+const SourceDefault = "default"
+
+// Provenance records where each formatter and lint setting of a Config
+// came from, keyed by dotted YAML path such as "formatter.tab_width".
+// Sources are SourceDefault, a config file path, a preset, an
+// .editorconfig section, or an override.
+type Provenance map[string]string
+
+// settingSections are the Config sections whose settings are tracked.
+var settingSections = []string{"formatter", "lint"}
+
+// defaultProvenance returns a Provenance with every setting attributed to
+// the defaults.
+func defaultProvenance() Provenance {
+	prov := Provenance{}
+	configFields := yamlFields(reflect.TypeFor[Config]())
+	for _, section := range settingSections {
+		for name := range yamlFields(configFields[section].Type) {
+			prov[section+"."+name] = SourceDefault
+		}
+	}
+	return prov
+}
+
+// record attributes the settings present in doc, a parsed config file,
+// to source.
+func (p Provenance) record(doc *yaml.Node, source string) {
+	if p == nil || doc.Kind != yaml.DocumentNode || len(doc.Content) == 0 {
+		return
+	}
+	for _, section := range settingSections {
+		p.recordSection(mappingValue(doc.Content[0], section), section, source)
+	}
+}
+
+// recordOverride attributes the settings of an applied override to it.
+func (p Provenance) recordOverride(o *Override) {
+	if p == nil {
+		return
+	}
+	source := fmt.Sprintf("override %s in %s", strings.Join(o.Files, ", "), o.source)
+	p.recordSection(&o.Formatter, "formatter", source)
+	p.recordSection(&o.Lint, "lint", source)
+}
+
+func (p Provenance) recordSection(n *yaml.Node, section, source string) {
+	if n == nil || n.Kind != yaml.MappingNode {
+		return
+	}
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		p[section+"."+n.Content[i].Value] = source
+	}
+}
+
+// mappingValue returns the value of key in mapping node n, or nil.
+func mappingValue(n *yaml.Node, key string) *yaml.Node {
+	if n.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		if n.Content[i].Value == key {
+			return n.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// Show renders the formatter and lint settings of cfg as YAML, with a
+// comment after each setting naming its source in prov.
+func Show(cfg *Config, prov Provenance) ([]byte, error) {
+	view := struct {
+		Formatter FormatterConfig `yaml:"formatter"`
+		Lint      LintConfig      `yaml:"lint"`
+	}{cfg.Formatter, cfg.Lint}
+
+	var doc yaml.Node
+	if err := doc.Encode(&view); err != nil {
+		return nil, err
+	}
+	for _, section := range settingSections {
+		n := mappingValue(&doc, section)
+		if n == nil {
+			continue
+		}
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			source, ok := prov[section+"."+n.Content[i].Value]
+			if !ok {
+				continue
+			}
+			// Block collections take the comment on their key line.
+			if value := n.Content[i+1]; value.Kind == yaml.ScalarNode || len(value.Content) == 0 {
+				value.LineComment = source
+			} else {
+				n.Content[i].LineComment = source
+			}
+		}
+	}
+
+	var b bytes.Buffer
+	enc := yaml.NewEncoder(&b)
+	enc.SetIndent(2)
+	if err := enc.Encode(&doc); err != nil {
+		return nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
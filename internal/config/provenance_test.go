@@ -0,0 +1,79 @@
+package config
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestResolverExplain(t *testing.T) {
+	repo := t.TempDir()
+	writeConfig(t, filepath.Join(repo, ".git", "HEAD"), "")
+	writeConfig(t, filepath.Join(repo, ".editorconfig"), "root = true\n\n[Makefile]\nindent_size = 8\n")
+	writeConfig(t, filepath.Join(repo, "makefmt.yml"), `extends: gnu
+formatter:
+  max_blank_lines: 3
+overrides:
+  - files: ["vendor/**"]
+    formatter:
+      assignment_spacing: preserve
+`)
+	writeConfig(t, filepath.Join(repo, "vendor", "makefmt.yml"), "lint:\n  rules:\n    a: warn\n")
+
+	cfg, prov, err := NewResolver("", nil).Explain(filepath.Join(repo, "vendor", "Makefile"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Formatter.AssignmentSpacing != "preserve" {
+		t.Errorf("AssignmentSpacing: got %q, want %q", cfg.Formatter.AssignmentSpacing, "preserve")
+	}
+
+	root := filepath.Join(repo, "makefmt.yml")
+	want := map[string]string{
+		"formatter.indent_style":        SourceDefault,
+		"formatter.tab_width":           "preset gnu",
+		"formatter.indent_conditionals": "preset gnu",
+		"formatter.max_blank_lines":     root,
+		"formatter.assignment_spacing":  "override vendor/** in " + root,
+		"lint.rules":                    filepath.Join(repo, "vendor", "makefmt.yml"),
+		"lint.exclude":                  SourceDefault,
+	}
+	for key, source := range want {
+		if prov[key] != source {
+			t.Errorf("%s: got source %q, want %q", key, prov[key], source)
+		}
+	}
+
+	// Without the preset, tab_width comes from .editorconfig.
+	writeConfig(t, filepath.Join(repo, "makefmt.yml"), "formatter:\n  max_blank_lines: 3\n")
+	_, prov, err = NewResolver("", nil).Explain(filepath.Join(repo, "Makefile"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := filepath.Join(repo, ".editorconfig") + " [Makefile]"; prov["formatter.tab_width"] != want {
+		t.Errorf("tab_width: got source %q, want %q", prov["formatter.tab_width"], want)
+	}
+}
+
+func TestShow(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Formatter.TabWidth = 8
+	cfg.Lint.Rules = map[string]string{"a": "warn"}
+	prov := defaultProvenance()
+	prov["formatter.tab_width"] = "makefmt.yml"
+	prov["lint.rules"] = "makefmt.yml"
+
+	out, err := Show(cfg, prov)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, want := range []string{
+		"formatter:\n  indent_style: tab # default\n  tab_width: 8 # makefmt.yml\n",
+		"lint:\n  rules: # makefmt.yml\n    a: warn\n  exclude: [] # default\n",
+	} {
+		if !strings.Contains(string(out), want) {
+			t.Errorf("Show output missing %q:\n%s", want, out)
+		}
+	}
+}
@@ -0,0 +1,194 @@
+package config
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+)
+
+// schemaID is the $id of the published JSON Schema.
+const schemaID = "https://github.com/donaldgifford/makefmt/raw/main/docs/makefmt.schema.json"
+
+// descriptions documents each config setting, keyed by its dotted YAML
+// path. Every field of Config must have an entry; see TestSchema.
+var descriptions = map[string]string{
+	"root":      "Stop config discovery at the directory containing this file.",
+	"extends":   "Built-in preset (gnu, minimal, strict) or config file, relative to this file, to load before this file's settings.",
+	"formatter": "Formatter settings.",
+	"lint":      "Lint settings (post-MVP).",
+	"overrides": "Settings applied to files matching glob patterns, in order.",
+
+	"formatter.indent_style":                  "Indentation character for recipes.",
+	"formatter.tab_width":                     "Tab display width, used for alignment calculations.",
+	"formatter.max_blank_lines":               "Maximum consecutive blank lines. Set to -1 to preserve all blank lines.",
+	"formatter.insert_final_newline":          "Ensure the file ends with exactly one newline.",
+	"formatter.trim_trailing_whitespace":      "Remove trailing spaces and tabs from every line.",
+	"formatter.align_assignments":             "Align assignment operators in consecutive assignment blocks (reserved for future use).",
+	"formatter.assignment_spacing":            "Spacing around assignment operators.",
+	"formatter.sort_prerequisites":            "Sort prerequisites alphabetically in rule declarations (reserved for future use).",
+	"formatter.align_backslash_continuations": "Align trailing backslashes in continuation blocks to a consistent column.",
+	"formatter.backslash_column":              "Target column for backslash alignment (1-indexed). Set to 0 to align to the longest line plus one space.",
+	"formatter.space_after_comment":           "Ensure a space after # in single-hash comments.",
+	"formatter.indent_conditionals":           "Indent the body of conditional blocks.",
+	"formatter.conditional_indent":            "Number of spaces for conditional indentation.",
+	"formatter.recipe_prefix":                 "Recipe line prefix handling. Only preserve is supported, which leaves recipe prefixes as written.",
+	"formatter.expand_inline_recipes":         "Move inline recipes (target: ; command) onto their own tab-indented recipe line.",
+	"formatter.align_inline_comments":         "Align trailing comments on consecutive lines into one column.",
+	"formatter.max_line_length":               "Wrap prerequisite lists, .PHONY lists and assignment values longer than this many columns onto continuation lines. Set to 0 to disable.",
+	"formatter.continuation_indent":           "Number of spaces that continuation lines are indented by, past the first line of an assignment or rule and past the tab of a recipe.",
+	"formatter.indent_continuations":          "Reindent continuation lines by continuation_indent spaces.",
+	"formatter.list_variables":                "Variable-name patterns (such as *_SOURCES) whose values are laid out as lists, one item per line.",
+	"formatter.list_layout":                   "Layout of list variables: expand puts every item on its own continuation line; collapse keeps the list on one line when it fits within max_line_length.",
+	"formatter.sort_list_items":               "Sort the items of list variables.",
+	"formatter.dedupe_list_items":             "Remove repeated items from list variables.",
+	"formatter.rules":                         "Turn formatting rules off (false) by name. A rule runs when its own settings turn it on and it is not set to false here.",
+
+	"lint.rules":   "Lint rule severity overrides, keyed by rule name.",
+	"lint.exclude": "File patterns to exclude from linting.",
+
+	"overrides.files":     "Glob patterns, relative to this file, selecting the files the override applies to.",
+	"overrides.formatter": "Formatter settings for matching files.",
+	"overrides.lint":      "Lint settings for matching files.",
+}
+
+// Schema returns a JSON Schema for makefmt config files, generated from
+// the Config type, its defaults, and the validation constraints.
+func Schema() ([]byte, error) {
+	g := &schemaGenerator{defs: map[string]any{}}
+	root := g.object(reflect.TypeFor[Config](), reflect.ValueOf(*DefaultConfig()), "")
+	root["$schema"] = "http://json-schema.org/draft-07/schema#"
+	root["$id"] = schemaID
+	root["title"] = "makefmt configuration"
+	root["definitions"] = g.defs
+
+	data, err := json.MarshalIndent(root, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
+
+type schemaGenerator struct {
+	defs map[string]any
+}
+
+// object returns the schema of struct type t. def holds the defaults for
+// its fields, or is the zero Value if there are none.
+func (g *schemaGenerator) object(t reflect.Type, def reflect.Value, path string) map[string]any {
+	props := map[string]any{}
+	for i := range t.NumField() {
+		f := t.Field(i)
+		name, _, _ := strings.Cut(f.Tag.Get("yaml"), ",")
+		if !f.IsExported() || name == "" || name == "-" {
+			continue
+		}
+
+		var fieldDef reflect.Value
+		if def.IsValid() {
+			fieldDef = def.Field(i)
+		}
+		props[name] = g.field(f.Type, fieldDef, join(path, name))
+	}
+	return map[string]any{
+		"type":                 "object",
+		"properties":           props,
+		"additionalProperties": false,
+	}
+}
+
+// field returns the schema of the setting at path.
+func (g *schemaGenerator) field(t reflect.Type, def reflect.Value, path string) map[string]any {
+	var s map[string]any
+	switch {
+	case t == reflect.TypeFor[FormatterConfig]() || t == reflect.TypeFor[LintConfig]():
+		s = g.ref(t, def)
+	case t == reflect.TypeFor[Override]():
+		s = g.object(reflect.TypeFor[overrideSchema](), reflect.Value{}, path)
+		s["required"] = []string{"files"}
+	case t.Kind() == reflect.Slice:
+		items := g.field(t.Elem(), reflect.Value{}, path)
+		delete(items, "description")
+		s = map[string]any{"type": "array", "items": items}
+	case t.Kind() == reflect.Map:
+		s = map[string]any{"type": "object", "additionalProperties": g.scalar(t.Elem(), path)}
+	default:
+		s = g.scalar(t, path)
+	}
+
+	if d, ok := descriptions[path]; ok {
+		s["description"] = d
+	}
+	if hasDefault(def) {
+		s["default"] = def.Interface()
+	}
+	return s
+}
+
+// hasDefault reports whether def is a default worth publishing. Structs
+// are described by their fields, and nil maps and slices are omitted.
+func hasDefault(def reflect.Value) bool {
+	if !def.IsValid() {
+		return false
+	}
+	switch def.Kind() {
+	case reflect.Struct:
+		return false
+	case reflect.Map, reflect.Slice:
+		return !def.IsNil()
+	default:
+		return true
+	}
+}
+
+// ref registers the definition of struct type t and returns a reference
+// to it.
+func (g *schemaGenerator) ref(t reflect.Type, def reflect.Value) map[string]any {
+	name := strings.ToLower(strings.TrimSuffix(t.Name(), "Config"))
+	if _, ok := g.defs[name]; !ok {
+		g.defs[name] = g.object(t, def, name)
+	}
+	return map[string]any{"$ref": "#/definitions/" + name}
+}
+
+// scalar returns the schema of a scalar setting, including the
+// constraints that Validate enforces.
+func (g *schemaGenerator) scalar(t reflect.Type, path string) map[string]any {
+	s := map[string]any{"type": jsonType(t)}
+
+	var c constraint
+	switch {
+	case strings.HasPrefix(path, "formatter."):
+		c = formatterConstraints[strings.TrimPrefix(path, "formatter.")]
+	case path == "lint.rules":
+		c = constraint{enum: lintSeverities}
+	}
+	if c.enum != nil {
+		s["enum"] = c.enum
+	}
+	if c.min != nil {
+		s["minimum"] = *c.min
+	}
+	return s
+}
+
+func jsonType(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Int:
+		return "integer"
+	case reflect.Map, reflect.Struct:
+		return "object"
+	case reflect.Slice:
+		return "array"
+	default:
+		return "string"
+	}
+}
+
+func join(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
@@ -0,0 +1,131 @@
+package config
+
+import (
+	"encoding/json"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/donaldgifford/makefmt/internal/testutil"
+)
+
+// schemaPath is the published schema, kept in sync with Schema by
+// TestSchemaFile. Regenerate it with go test ./internal/config -update.
+const schemaPath = "../../docs/makefmt.schema.json"
+
+func TestSchemaFile(t *testing.T) {
+	got, err := Schema()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if *testutil.Update {
+		if err := os.WriteFile(schemaPath, got, 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+
+	want, err := os.ReadFile(schemaPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != string(want) {
+		t.Errorf("%s is out of date; run go test ./internal/config -update", schemaPath)
+	}
+}
+
+func TestSchemaDescribesEveryField(t *testing.T) {
+	paths := map[string]bool{}
+	var walk func(t reflect.Type, prefix string)
+	walk = func(t reflect.Type, prefix string) {
+		for name, f := range yamlFields(t) {
+			path := join(prefix, name)
+			paths[path] = true
+
+			ft := f.Type
+			if ft.Kind() == reflect.Slice {
+				ft = ft.Elem()
+			}
+			if ft == reflect.TypeFor[Override]() {
+				ft = reflect.TypeFor[overrideSchema]()
+			}
+			if ft.Kind() == reflect.Struct && !strings.HasPrefix(path, "overrides.") {
+				walk(ft, path)
+			}
+		}
+	}
+	walk(reflect.TypeFor[Config](), "")
+
+	for path := range paths {
+		if descriptions[path] == "" {
+			t.Errorf("no description for %s", path)
+		}
+	}
+	for path := range descriptions {
+		if !paths[path] {
+			t.Errorf("description for unknown field %s", path)
+		}
+	}
+}
+
+func TestSchemaMatchesConfig(t *testing.T) {
+	data, err := Schema()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var schema struct {
+		Properties  map[string]json.RawMessage `json:"properties"`
+		Definitions map[string]struct {
+			Properties map[string]struct {
+				Type    string   `json:"type"`
+				Enum    []string `json:"enum"`
+				Default any      `json:"default"`
+			} `json:"properties"`
+		} `json:"definitions"`
+	}
+	if err := json.Unmarshal(data, &schema); err != nil {
+		t.Fatal(err)
+	}
+
+	for name := range yamlFields(reflect.TypeFor[Config]()) {
+		if _, ok := schema.Properties[name]; !ok {
+			t.Errorf("schema missing top-level property %s", name)
+		}
+	}
+
+	formatter := schema.Definitions["formatter"].Properties
+	def := reflect.ValueOf(DefaultConfig().Formatter)
+	for name, f := range yamlFields(reflect.TypeFor[FormatterConfig]()) {
+		prop, ok := formatter[name]
+		if !ok {
+			t.Errorf("schema missing formatter.%s", name)
+			continue
+		}
+		if prop.Type != jsonType(f.Type) {
+			t.Errorf("formatter.%s: type %s, want %s", name, prop.Type, jsonType(f.Type))
+		}
+
+		if !hasDefault(def.FieldByIndex(f.Index)) {
+			if prop.Default != nil {
+				t.Errorf("formatter.%s: unexpected default %v", name, prop.Default)
+			}
+			continue
+		}
+
+		// JSON numbers decode as float64.
+		want := def.FieldByIndex(f.Index).Interface()
+		if n, ok := want.(int); ok {
+			want = float64(n)
+		}
+		if prop.Default != want {
+			t.Errorf("formatter.%s: default %v, want %v", name, prop.Default, want)
+		}
+
+		if c, ok := formatterConstraints[name]; ok && !reflect.DeepEqual(prop.Enum, c.enum) {
+			t.Errorf("formatter.%s: enum %v, want %v", name, prop.Enum, c.enum)
+		}
+	}
+}
@@ -0,0 +1,166 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Setting is a single config value given on the command line or in the
+// environment. Settings are applied after every config file, in the
+// order they are given.
+type Setting struct {
+	// Key is the dotted YAML path of the setting, such as
+	// "formatter.tab_width". Formatter settings may omit the
+	// "formatter." prefix, as in "rules.<name>"; lint rules are set as
+	// "lint.rules.<name>".
+	Key   string
+	Value string
+
+	// Source names the flag, such as "--set", or the environment
+	// variable that gave the setting.
+	Source string
+}
+
+// ParseSetting parses a key=value setting given with the --set flag.
+func ParseSetting(s string) (Setting, error) {
+	key, value, ok := strings.Cut(s, "=")
+	key = strings.TrimSpace(key)
+	if !ok || key == "" {
+		return Setting{}, fmt.Errorf("invalid setting %q: want key=value", s)
+	}
+	return Setting{Key: key, Value: value, Source: "--set"}, nil
+}
+
+// String returns the setting as it was given.
+func (s Setting) String() string {
+	if s.Source == "--set" {
+		return s.Source + " " + s.Key + "=" + s.Value
+	}
+	return s.Source + "=" + s.Value
+}
+
+// origin describes where the setting came from, for provenance.
+func (s Setting) origin() string {
+	if strings.HasPrefix(s.Source, "-") {
+		return "flag " + s.Source
+	}
+	return "env " + s.Source
+}
+
+// document returns a config document that sets s, after checking it
+// like a config file against the given rule names.
+func (s Setting) document(rules []string) (*yaml.Node, error) {
+	path := strings.Split(s.Key, ".")
+	if !slices.Contains(settingSections, path[0]) {
+		path = append([]string{"formatter"}, path...)
+	}
+
+	node := &yaml.Node{Kind: yaml.ScalarNode, Value: s.Value}
+	if t := settingType(path); t != nil && (t.Kind() == reflect.Slice || t.Kind() == reflect.Map) {
+		node = collectionNode(t, s.Value)
+	}
+	for i := len(path) - 1; i >= 0; i-- {
+		node = &yaml.Node{
+			Kind:    yaml.MappingNode,
+			Content: []*yaml.Node{{Kind: yaml.ScalarNode, Value: path[i]}, node},
+		}
+	}
+	doc := &yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{node}}
+
+	if err := validateDocument(s.String(), doc, rules); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+// settingType returns the Go type of the setting at path, or nil if there
+// is no such setting.
+func settingType(path []string) reflect.Type {
+	t := reflect.TypeFor[Config]()
+	for _, name := range path {
+		switch t.Kind() {
+		case reflect.Struct:
+			f, ok := yamlFields(t)[name]
+			if !ok {
+				return nil
+			}
+			t = f.Type
+		case reflect.Map:
+			t = t.Elem()
+		default:
+			return nil
+		}
+	}
+	return t
+}
+
+// collectionNode parses value as a list or map setting of type t. Either
+// takes YAML flow syntax, such as [a, b] or {a: false}; a list may also
+// be given as comma-separated items, with or without the brackets.
+func collectionNode(t reflect.Type, value string) *yaml.Node {
+	var doc yaml.Node
+	if err := yaml.Unmarshal([]byte(value), &doc); err == nil && len(doc.Content) == 1 {
+		if n := doc.Content[0]; n.Kind == yaml.SequenceNode || n.Kind == yaml.MappingNode {
+			clearPositions(n)
+			return n
+		}
+	}
+	if t.Kind() != reflect.Slice {
+		return &yaml.Node{Kind: yaml.ScalarNode, Value: value}
+	}
+
+	seq := &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+	value = strings.TrimSuffix(strings.TrimPrefix(strings.TrimSpace(value), "["), "]")
+	for item := range strings.SplitSeq(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			seq.Content = append(seq.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: item})
+		}
+	}
+	return seq
+}
+
+// clearPositions removes the line and column of n and its children, which
+// refer to the setting's value rather than to a file.
+func clearPositions(n *yaml.Node) {
+	n.Line, n.Column = 0, 0
+	for _, c := range n.Content {
+		clearPositions(c)
+	}
+}
+
+// flagSettings holds validated settings ready to apply.
+type flagSettings struct {
+	docs    []*yaml.Node
+	sources []string
+}
+
+func (f *flagSettings) add(s Setting, rules []string) error {
+	doc, err := s.document(rules)
+	if err != nil {
+		return err
+	}
+	f.docs = append(f.docs, doc)
+	f.sources = append(f.sources, s.origin())
+	return nil
+}
+
+// apply returns cfg with the settings applied, leaving cfg unchanged. If
+// prov is non-nil, it records the flag or variable each setting came from.
+func (f *flagSettings) apply(cfg *Config, prov Provenance) (*Config, error) {
+	if len(f.docs) == 0 {
+		return cfg, nil
+	}
+
+	cfg = cfg.clone()
+	for i, doc := range f.docs {
+		if err := doc.Decode(cfg); err != nil {
+			return nil, fmt.Errorf("%s: %w", f.sources[i], err)
+		}
+		prov.record(doc, f.sources[i])
+	}
+	return cfg, nil
+}
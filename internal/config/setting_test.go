@@ -0,0 +1,158 @@
+package config
+
+import (
+	"maps"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestParseSetting(t *testing.T) {
+	s, err := ParseSetting("tab_width=8")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Key != "tab_width" || s.Value != "8" || s.Source != "--set" {
+		t.Errorf("ParseSetting = %+v", s)
+	}
+
+	for _, bad := range []string{"tab_width", "=8", ""} {
+		if _, err := ParseSetting(bad); err == nil {
+			t.Errorf("ParseSetting(%q): expected error", bad)
+		}
+	}
+}
+
+func TestResolverSet(t *testing.T) {
+	dir := t.TempDir()
+	writeConfig(t, filepath.Join(dir, ".git", "HEAD"), "")
+	writeConfig(t, filepath.Join(dir, "makefmt.yml"), `formatter:
+  max_blank_lines: 1
+overrides:
+  - files: ["*.mk"]
+    formatter:
+      tab_width: 2
+`)
+
+	r := NewResolver("", nil)
+	err := r.Set(
+		Setting{Key: "tab_width", Value: "8", Source: "--set"},
+		Setting{Key: "formatter.indent_conditionals", Value: "false", Source: "--indent-conditionals"},
+		Setting{Key: "lint.rules.foo", Value: "warn", Source: "--set"},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, prov, err := r.Explain(filepath.Join(dir, "rules.mk"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Formatter.TabWidth != 8 {
+		t.Errorf("TabWidth: got %d, want 8 (flag beats override)", cfg.Formatter.TabWidth)
+	}
+	if cfg.Formatter.IndentConditionals {
+		t.Error("IndentConditionals: got true, want false")
+	}
+	if cfg.Formatter.MaxBlankLines != 1 {
+		t.Errorf("MaxBlankLines: got %d, want 1 (config file)", cfg.Formatter.MaxBlankLines)
+	}
+	if cfg.Lint.Rules["foo"] != "warn" {
+		t.Errorf("Lint.Rules: got %v, want foo=warn", cfg.Lint.Rules)
+	}
+	if prov["formatter.tab_width"] != "flag --set" || prov["formatter.indent_conditionals"] != "flag --indent-conditionals" {
+		t.Errorf("provenance: got %v", prov)
+	}
+
+	// ForDir and ForFile apply the settings too, without changing the
+	// cached config.
+	for i := range 2 {
+		cfg, err = r.ForDir(dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if cfg.Formatter.TabWidth != 8 {
+			t.Errorf("ForDir call %d: TabWidth got %d, want 8", i, cfg.Formatter.TabWidth)
+		}
+	}
+}
+
+func TestResolverSetInvalid(t *testing.T) {
+	tests := []struct {
+		setting Setting
+		want    string
+	}{
+		{Setting{Key: "tab_widht", Value: "8", Source: "--set"}, `--set tab_widht=8: unknown field "tab_widht" (did you mean tab_width?)`},
+		{Setting{Key: "formatter.max_blank_lines", Value: "x", Source: "--max-blank-lines"}, `--max-blank-lines=x: invalid value "x": expected an integer`},
+		{Setting{Key: "assignment_spacing", Value: "spaces", Source: "--set"}, `must be one of space, no_space, preserve`},
+		{Setting{Key: "overrides.files", Value: "x", Source: "--set"}, `unknown field "overrides"`},
+	}
+
+	for _, tt := range tests {
+		err := NewResolver("", nil).Set(tt.setting)
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("Set(%v): got %v, want error containing %q", tt.setting, err, tt.want)
+		}
+	}
+}
+
+func TestResolverSetCollections(t *testing.T) {
+	tests := []struct {
+		name     string
+		settings []Setting
+		want     []string
+		rules    map[string]bool
+	}{
+		{
+			name:     "single item",
+			settings: []Setting{{Key: "list_variables", Value: "SRCS", Source: "--set"}},
+			want:     []string{"SRCS"},
+		},
+		{
+			name:     "comma-separated",
+			settings: []Setting{{Key: "list_variables", Value: "*_SOURCES, *_OBJS", Source: "MAKEFMT_LIST_VARIABLES"}},
+			want:     []string{"*_SOURCES", "*_OBJS"},
+		},
+		{
+			name:     "flow sequence",
+			settings: []Setting{{Key: "formatter.list_variables", Value: "[SRCS, OBJS]", Source: "--set"}},
+			want:     []string{"SRCS", "OBJS"},
+		},
+		{
+			name:     "brackets around patterns",
+			settings: []Setting{{Key: "list_variables", Value: "[*_SOURCES]", Source: "--set"}},
+			want:     []string{"*_SOURCES"},
+		},
+		{
+			name:     "flow mapping",
+			settings: []Setting{{Key: "rules", Value: "{max_line_length: false, trim_trailing_whitespace: true}", Source: "--set"}},
+			rules:    map[string]bool{"max_line_length": false, "trim_trailing_whitespace": true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewResolver("", nil)
+			if err := r.Set(tt.settings...); err != nil {
+				t.Fatal(err)
+			}
+			cfg, err := r.ForDir(t.TempDir())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.want != nil && !slices.Equal(cfg.Formatter.ListVariables, tt.want) {
+				t.Errorf("ListVariables: got %q, want %q", cfg.Formatter.ListVariables, tt.want)
+			}
+			if tt.rules != nil && !maps.Equal(cfg.Formatter.Rules, tt.rules) {
+				t.Errorf("Rules: got %v, want %v", cfg.Formatter.Rules, tt.rules)
+			}
+		})
+	}
+
+	// A map setting given a plain value is reported without a position.
+	err := NewResolver("", nil).Set(Setting{Key: "rules", Value: "off", Source: "--set"})
+	if err == nil || err.Error() != "--set rules=off: expected a mapping" {
+		t.Errorf("got %v, want expected a mapping error", err)
+	}
+}
@@ -0,0 +1,121 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Template returns a makefmt.yml that sets every option to its default,
+// with a comment describing each one.
+func Template() []byte {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# makefmt configuration. Every option is listed with its default value.\n")
+	fmt.Fprintf(&b, "# yaml-language-server: $schema=%s\n", schemaID)
+
+	writeTemplate(&b, reflect.TypeFor[Config](), reflect.ValueOf(*DefaultConfig()), "", 0)
+	return []byte(b.String())
+}
+
+// writeTemplate writes the fields of struct type t, with values from v,
+// indented by depth levels.
+func writeTemplate(b *strings.Builder, t reflect.Type, v reflect.Value, path string, depth int) {
+	indent := strings.Repeat("  ", depth)
+	first := true
+	for i := range t.NumField() {
+		f := t.Field(i)
+		name, _, _ := strings.Cut(f.Tag.Get("yaml"), ",")
+		if !f.IsExported() || name == "" || name == "-" {
+			continue
+		}
+		key := join(path, name)
+
+		// Separate settings with a blank line, except directly after the
+		// section they belong to.
+		if !first || depth == 0 {
+			b.WriteString("\n")
+		}
+		first = false
+		for _, line := range templateComments(key, f.Type, v.Field(i)) {
+			fmt.Fprintf(b, "%s# %s\n", indent, line)
+		}
+
+		if f.Type.Kind() == reflect.Struct {
+			fmt.Fprintf(b, "%s%s:\n", indent, name)
+			writeTemplate(b, f.Type, v.Field(i), key, depth+1)
+			continue
+		}
+		fmt.Fprintf(b, "%s%s: %s\n", indent, name, templateValue(f.Type, v.Field(i)))
+	}
+}
+
+// templateComments returns the comment lines describing the setting at
+// path: its description, allowed values and default.
+func templateComments(path string, t reflect.Type, v reflect.Value) []string {
+	lines := wrapWords(descriptions[path], 72)
+	if t.Kind() == reflect.Struct {
+		return lines
+	}
+
+	if c, ok := formatterConstraints[strings.TrimPrefix(path, "formatter.")]; ok && strings.HasPrefix(path, "formatter.") {
+		if c.enum != nil {
+			lines = append(lines, "Options: "+strings.Join(c.enum, ", "))
+		}
+		if c.min != nil {
+			lines = append(lines, fmt.Sprintf("Minimum: %d", *c.min))
+		}
+	}
+	if path == "lint.rules" {
+		lines = append(lines, "Severities: "+strings.Join(lintSeverities, ", "))
+	}
+	return append(lines, "Default: "+templateValue(t, v))
+}
+
+// templateValue renders a default value in flow style.
+func templateValue(t reflect.Type, v reflect.Value) string {
+	switch t.Kind() {
+	case reflect.Map:
+		if v.Len() == 0 {
+			return "{}"
+		}
+	case reflect.Slice:
+		if v.Len() == 0 {
+			return "[]"
+		}
+	case reflect.String:
+		if v.String() == "" {
+			return `""`
+		}
+	}
+
+	node := &yaml.Node{}
+	if err := node.Encode(v.Interface()); err != nil {
+		return fmt.Sprint(v.Interface())
+	}
+	node.Style = yaml.FlowStyle
+	out, err := yaml.Marshal(node)
+	if err != nil {
+		return fmt.Sprint(v.Interface())
+	}
+	return strings.TrimSpace(string(out))
+}
+
+// wrapWords splits text into lines of at most width bytes, breaking at
+// spaces. Words longer than width are kept whole.
+func wrapWords(text string, width int) []string {
+	var lines []string
+	line := ""
+	for _, word := range strings.Fields(text) {
+		if line != "" && len(line)+1+len(word) > width {
+			lines = append(lines, line)
+			line = ""
+		}
+		if line != "" {
+			line += " "
+		}
+		line += word
+	}
+	return append(lines, line)
+}
@@ -0,0 +1,51 @@
+package config
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestTemplateLoadsAsDefaults(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "makefmt.yml")
+	writeConfig(t, path, string(Template()))
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load(template): %v", err)
+	}
+
+	want := DefaultConfig()
+	want.Formatter.Rules = map[string]bool{}
+	want.Formatter.ListVariables = []string{}
+	want.Lint.Rules = map[string]string{}
+	want.Lint.Exclude = []string{}
+	if !reflect.DeepEqual(cfg.Formatter, want.Formatter) || !reflect.DeepEqual(cfg.Lint, want.Lint) {
+		t.Errorf("template config differs from defaults:\ngot  %+v\nwant %+v", cfg, want)
+	}
+}
+
+func TestTemplateDocumentsEverySetting(t *testing.T) {
+	tmpl := string(Template())
+	for path := range defaultProvenance() {
+		name := path[strings.LastIndexByte(path, '.')+1:]
+		if !strings.Contains(tmpl, "\n  "+name+": ") {
+			t.Errorf("template is missing %s", path)
+		}
+	}
+	for _, line := range strings.Split(tmpl, "\n") {
+		// The schema modeline must stay on one line.
+		if len(line) > 80 && !strings.Contains(line, "$schema=") {
+			t.Errorf("template line longer than 80 columns: %q", line)
+		}
+	}
+}
+
+func TestWrapWords(t *testing.T) {
+	got := wrapWords("one two three four", 9)
+	want := []string{"one two", "three", "four"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("wrapWords = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,274 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// FieldError describes an invalid setting in a config file.
+type FieldError struct {
+	File    string
+	Line    int
+	Column  int
+	Message string
+}
+
+func (e *FieldError) Error() string {
+	// Settings from the command line have no position.
+	if e.Line == 0 {
+		return fmt.Sprintf("%s: %s", e.File, e.Message)
+	}
+	return fmt.Sprintf("%s:%d:%d: %s", e.File, e.Line, e.Column, e.Message)
+}
+
+// constraint restricts the values of one setting.
+type constraint struct {
+	enum []string
+	min  *int
+	keys []string // Allowed keys of a map setting.
+}
+
+func atLeast(n int) *int { return &n }
+
+// formatterConstraints holds the allowed values of formatter settings,
+// keyed by YAML name.
+var formatterConstraints = map[string]constraint{
+	"indent_style":        {enum: []string{"tab"}},
+	"tab_width":           {min: atLeast(1)},
+	"max_blank_lines":     {min: atLeast(-1)},
+	"assignment_spacing":  {enum: []string{"space", "no_space", "preserve"}},
+	"backslash_column":    {min: atLeast(0)},
+	"conditional_indent":  {min: atLeast(0)},
+	"recipe_prefix":       {enum: []string{"preserve"}},
+	"max_line_length":     {min: atLeast(0)},
+	"continuation_indent": {min: atLeast(0)},
+	"list_layout":         {enum: []string{"expand", "collapse"}},
+}
+
+// lintSeverities are the allowed values of lint.rules entries.
+var lintSeverities = []string{"off", "warn", "error"}
+
+// overrideSchema describes the YAML shape of an Override, whose settings
+// are kept as raw nodes until they are applied.
+type overrideSchema struct {
+	Files     []string        `yaml:"files"`
+	Formatter FormatterConfig `yaml:"formatter"`
+	Lint      LintConfig      `yaml:"lint"`
+}
+
+// validator checks a parsed config document against the Config type and
+// collects every problem it finds.
+type validator struct {
+	file  string
+	rules []string // Allowed keys of formatter.rules; nil allows any.
+	errs  []error
+}
+
+// validateDocument checks that doc, the root node of a config file, only
+// uses known settings with valid values. If rules is non-nil, the keys of
+// formatter.rules must be among its names. It returns a *FieldError for
+// each problem, joined with errors.Join.
+func validateDocument(file string, doc *yaml.Node, rules []string) error {
+	v := &validator{file: file, rules: rules}
+	if doc.Kind == yaml.DocumentNode && len(doc.Content) > 0 {
+		v.check(doc.Content[0], reflect.TypeFor[Config](), nil)
+	}
+	return errors.Join(v.errs...)
+}
+
+func (v *validator) errorf(n *yaml.Node, format string, args ...any) {
+	v.errs = append(v.errs, &FieldError{
+		File:    v.file,
+		Line:    n.Line,
+		Column:  n.Column,
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+// check validates n against type t. c constrains scalar values.
+func (v *validator) check(n *yaml.Node, t reflect.Type, c *constraint) {
+	if n.Kind == yaml.AliasNode {
+		n = n.Alias
+	}
+	if n.Kind == yaml.ScalarNode && n.Tag == "!!null" {
+		return
+	}
+	if t == reflect.TypeFor[Override]() {
+		t = reflect.TypeFor[overrideSchema]()
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		v.checkStruct(n, t)
+	case reflect.Map:
+		if n.Kind != yaml.MappingNode {
+			v.errorf(n, "expected a mapping")
+			return
+		}
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			key := n.Content[i]
+			if c != nil && c.keys != nil && !slices.Contains(c.keys, key.Value) {
+				v.unknownKey(key, "rule", c.keys)
+				continue
+			}
+			v.check(n.Content[i+1], t.Elem(), c)
+		}
+	case reflect.Slice:
+		if n.Kind != yaml.SequenceNode {
+			v.errorf(n, "expected a list")
+			return
+		}
+		for _, item := range n.Content {
+			v.check(item, t.Elem(), c)
+		}
+	default:
+		v.checkScalar(n, t, c)
+	}
+}
+
+// checkStruct validates a mapping against the fields of struct type t.
+func (v *validator) checkStruct(n *yaml.Node, t reflect.Type) {
+	if n.Kind != yaml.MappingNode {
+		v.errorf(n, "expected a mapping")
+		return
+	}
+
+	fields := yamlFields(t)
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		key, value := n.Content[i], n.Content[i+1]
+		field, ok := fields[key.Value]
+		if !ok {
+			v.unknownField(key, fields)
+			continue
+		}
+
+		var c *constraint
+		switch {
+		case t == reflect.TypeFor[FormatterConfig]():
+			if fc, ok := formatterConstraints[key.Value]; ok {
+				c = &fc
+			}
+		case t == reflect.TypeFor[LintConfig]() && key.Value == "rules":
+			c = &constraint{enum: lintSeverities}
+		}
+		if t == reflect.TypeFor[FormatterConfig]() && key.Value == "rules" && v.rules != nil {
+			c = &constraint{keys: v.rules}
+		}
+		v.check(value, field.Type, c)
+	}
+}
+
+func (v *validator) unknownField(key *yaml.Node, fields map[string]reflect.StructField) {
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	v.unknownKey(key, "field", names)
+}
+
+// unknownKey reports a key that is not one of names, suggesting the
+// closest match.
+func (v *validator) unknownKey(key *yaml.Node, kind string, names []string) {
+	if s := Suggest(key.Value, names); s != "" {
+		v.errorf(key, "unknown %s %q (did you mean %s?)", kind, key.Value, s)
+		return
+	}
+	names = slices.Sorted(slices.Values(names))
+	v.errorf(key, "unknown %s %q (valid %ss: %s)", kind, key.Value, kind, strings.Join(names, ", "))
+}
+
+// checkScalar validates a scalar value of kind t.Kind() against c.
+func (v *validator) checkScalar(n *yaml.Node, t reflect.Type, c *constraint) {
+	if n.Kind != yaml.ScalarNode {
+		v.errorf(n, "expected %s", kindName(t))
+		return
+	}
+
+	value := reflect.New(t)
+	if err := n.Decode(value.Interface()); err != nil {
+		v.errorf(n, "invalid value %q: expected %s", n.Value, kindName(t))
+		return
+	}
+	if c == nil {
+		return
+	}
+
+	if c.enum != nil && !slices.Contains(c.enum, n.Value) {
+		msg := fmt.Sprintf("invalid value %q: must be one of %s", n.Value, strings.Join(c.enum, ", "))
+		if s := Suggest(n.Value, c.enum); s != "" {
+			msg += fmt.Sprintf(" (did you mean %s?)", s)
+		}
+		v.errorf(n, "%s", msg)
+	}
+	if c.min != nil && value.Elem().Kind() == reflect.Int && value.Elem().Int() < int64(*c.min) {
+		v.errorf(n, "invalid value %s: must be at least %d", n.Value, *c.min)
+	}
+}
+
+// kindName describes the scalar kind of t for error messages.
+func kindName(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.Bool:
+		return "a boolean"
+	case reflect.Int:
+		return "an integer"
+	default:
+		return "a " + t.Kind().String()
+	}
+}
+
+// yamlFields returns the fields of struct type t keyed by YAML name.
+func yamlFields(t reflect.Type) map[string]reflect.StructField {
+	fields := make(map[string]reflect.StructField)
+	for i := range t.NumField() {
+		f := t.Field(i)
+		name, _, _ := strings.Cut(f.Tag.Get("yaml"), ",")
+		if !f.IsExported() || name == "" || name == "-" {
+			continue
+		}
+		fields[name] = f
+	}
+	return fields
+}
+
+// Suggest returns the candidate closest to s by edit distance, or an
+// empty string if none is close enough to be a likely typo.
+func Suggest(s string, candidates []string) string {
+	best, bestDist := "", 0
+	for _, c := range candidates {
+		d := levenshtein(s, c)
+		if d > max(2, len(c)/3) {
+			continue
+		}
+		if best == "" || d < bestDist || (d == bestDist && c < best) {
+			best, bestDist = c, d
+		}
+	}
+	return best
+}
+
+// levenshtein returns the edit distance between a and b.
+func levenshtein(a, b string) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(b)]
+}
@@ -0,0 +1,141 @@
+package config
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestValidateErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    []string
+	}{
+		{
+			name:    "unknown field with suggestion",
+			content: "formatter:\n  max_blank_line: 1\n",
+			want:    []string{`2:3: unknown field "max_blank_line" (did you mean max_blank_lines?)`},
+		},
+		{
+			name:    "unknown top-level field",
+			content: "formater:\n  tab_width: 2\n",
+			want:    []string{`1:1: unknown field "formater" (did you mean formatter?)`},
+		},
+		{
+			name:    "unknown field without suggestion",
+			content: "colour: blue\n",
+			want:    []string{`1:1: unknown field "colour" (valid fields:`},
+		},
+		{
+			name:    "invalid enum",
+			content: "formatter:\n  assignment_spacing: spaces\n",
+			want:    []string{`2:23: invalid value "spaces": must be one of space, no_space, preserve (did you mean space?)`},
+		},
+		{
+			name:    "out of range",
+			content: "formatter:\n  conditional_indent: -3\n",
+			want:    []string{`2:23: invalid value -3: must be at least 0`},
+		},
+		{
+			name:    "wrong type",
+			content: "formatter:\n  tab_width: wide\n  indent_conditionals: maybe\n",
+			want: []string{
+				`2:14: invalid value "wide": expected an integer`,
+				`3:24: invalid value "maybe": expected a boolean`,
+			},
+		},
+		{
+			name:    "lint severity",
+			content: "lint:\n  rules:\n    foo: fatal\n",
+			want:    []string{`3:10: invalid value "fatal": must be one of off, warn, error`},
+		},
+		{
+			name:    "override settings",
+			content: "overrides:\n  - files: [\"*.mk\"]\n    formatter:\n      tab_widht: 2\n",
+			want:    []string{`4:7: unknown field "tab_widht" (did you mean tab_width?)`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "makefmt.yml")
+			writeConfig(t, path, tt.content)
+
+			_, err := Load(path)
+			if err == nil {
+				t.Fatal("expected error")
+			}
+			var fieldErr *FieldError
+			if !errors.As(err, &fieldErr) {
+				t.Fatalf("expected *FieldError, got %T: %v", err, err)
+			}
+
+			lines := strings.Split(err.Error(), "\n")
+			if len(lines) != len(tt.want) {
+				t.Fatalf("got %d errors, want %d:\n%v", len(lines), len(tt.want), err)
+			}
+			for i, want := range tt.want {
+				if !strings.HasPrefix(lines[i], path+":"+want) {
+					t.Errorf("error %d:\ngot  %s\nwant %s:%s", i, lines[i], path, want)
+				}
+			}
+		})
+	}
+}
+
+func TestValidatePresets(t *testing.T) {
+	for name, preset := range presets {
+		var doc yaml.Node
+		if err := yaml.Unmarshal([]byte(preset), &doc); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if err := validateDocument(name, &doc, nil); err != nil {
+			t.Errorf("preset %s: %v", name, err)
+		}
+	}
+}
+
+func TestSuggest(t *testing.T) {
+	candidates := []string{"tab_width", "max_blank_lines", "indent_style"}
+
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"tab_widht", "tab_width"},
+		{"max_blank_line", "max_blank_lines"},
+		{"indent", ""},
+		{"colour", ""},
+	}
+	for _, tt := range tests {
+		if got := Suggest(tt.in, candidates); got != tt.want {
+			t.Errorf("Suggest(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestValidateRuleNames(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "makefmt.yml")
+	writeConfig(t, path, "formatter:\n  rules:\n    max_blank_lines: false\n    trim_trailing_whitespac: false\n")
+
+	r := NewResolver(path, []string{"trim_trailing_whitespace", "max_blank_lines"})
+	_, err := r.ForDir(".")
+	want := path + `:4:5: unknown rule "trim_trailing_whitespac" (did you mean trim_trailing_whitespace?)`
+	if err == nil || err.Error() != want {
+		t.Errorf("got %v, want %s", err, want)
+	}
+
+	err = r.Set(Setting{Key: "rules.max_blank_line", Value: "false", Source: "--set"})
+	if err == nil || !strings.Contains(err.Error(), `unknown rule "max_blank_line"`) {
+		t.Errorf("Set: got %v, want unknown rule error", err)
+	}
+
+	// Without rule names, any rule name is accepted.
+	if _, err := Load(path); err != nil {
+		t.Errorf("Load without rule names: %v", err)
+	}
+}
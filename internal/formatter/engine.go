@@ -3,14 +3,42 @@ package formatter
 import (
 	"github.com/donaldgifford/makefmt/internal/config"
 	"github.com/donaldgifford/makefmt/internal/parser"
+	"github.com/donaldgifford/makefmt/pkg/diff"
 )
 
-// Run applies each formatting rule in order, piping the output of one
-// as input to the next.
+// Run applies each enabled formatting rule in order, piping the output
+// of one as input to the next. Nodes covered by "# makefmt:" suppression
+// comments are left as they were parsed.
 func Run(nodes []*parser.Node, cfg *config.FormatterConfig, rules []FormatRule) []*parser.Node {
+	supp := parser.FindSuppressions(nodes)
+
 	result := nodes
 	for _, rule := range rules {
-		result = rule.Format(result, cfg)
+		if !Enabled(rule, cfg) {
+			continue
+		}
+		out := rule.Format(result, cfg)
+		if supp.Affects(rule.Name()) {
+			out = restore(result, out, func(line int) bool {
+				return supp.Suppressed(line, rule.Name())
+			})
+		}
+		result = out
 	}
 	return result
 }
+
+// Format parses src, applies rules, and returns the formatted text.
+func Format(src string, cfg *config.FormatterConfig, rules []FormatRule) string {
+	return Write(Run(parser.Parse(src), cfg, rules))
+}
+
+// FormatRange formats src but keeps only the changes that touch lines
+// startLine through endLine (1-indexed, inclusive). Rules still see the
+// whole file, so context such as conditional depth is honored. The result
+// is returned as edits against src.
+func FormatRange(src string, cfg *config.FormatterConfig, rules []FormatRule, startLine, endLine int) []diff.Edit {
+	formatted := Format(src, cfg, rules)
+	restricted := diff.Restrict(src, formatted, []diff.LineRange{{Start: startLine, End: endLine}})
+	return diff.Edits(src, restricted)
+}
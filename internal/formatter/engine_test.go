@@ -0,0 +1,169 @@
+package formatter_test
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/donaldgifford/makefmt/internal/config"
+	"github.com/donaldgifford/makefmt/internal/formatter"
+	"github.com/donaldgifford/makefmt/internal/rules"
+	"github.com/donaldgifford/makefmt/pkg/diff"
+)
+
+func TestFormatRange(t *testing.T) {
+	cfg := config.DefaultConfig()
+	src := "A:=1\nB:=2\nC:=3\n"
+
+	tests := []struct {
+		name       string
+		start, end int
+		want       string
+	}{
+		{"first line", 1, 1, "A := 1\nB:=2\nC:=3\n"},
+		{"middle lines", 2, 3, "A:=1\nB := 2\nC := 3\n"},
+		{"whole file", 1, 3, "A := 1\nB := 2\nC := 3\n"},
+		{"past end", 10, 20, src},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			edits := formatter.FormatRange(src, &cfg.Formatter, rules.FormatRules(), tt.start, tt.end)
+			if got := diff.Apply(src, edits); got != tt.want {
+				t.Errorf("want: %q\ngot:  %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestFormatRangeUsesFullContext(t *testing.T) {
+	cfg := config.DefaultConfig()
+	src := "ifdef DEBUG\nA:=1\nB:=2\nendif\n"
+
+	edits := formatter.FormatRange(src, &cfg.Formatter, rules.FormatRules(), 3, 3)
+	want := "ifdef DEBUG\nA:=1\n  B := 2\nendif\n"
+	if got := diff.Apply(src, edits); got != want {
+		t.Errorf("want: %q\ngot:  %q", want, got)
+	}
+}
+
+func TestRunSkipsDisabledRules(t *testing.T) {
+	cfg := config.DefaultConfig()
+	src := "A:=1   \n# comment\n"
+
+	cfg.Formatter.Rules = map[string]bool{"assignment_spacing": false}
+	if got, want := formatter.Format(src, &cfg.Formatter, rules.FormatRules()), "A:=1\n# comment\n"; got != want {
+		t.Errorf("assignment_spacing off: got %q, want %q", got, want)
+	}
+
+	cfg.Formatter.Rules = map[string]bool{"assignment_spacing": true, "trim_trailing_whitespace": false}
+	if got, want := formatter.Format("all:\n\techo hi   \n", &cfg.Formatter, rules.FormatRules()), "all:\n\techo hi   \n"; got != want {
+		t.Errorf("trim_trailing_whitespace off: got %q, want %q", got, want)
+	}
+}
+
+func TestRuleNamesAndDescriptions(t *testing.T) {
+	seen := map[string]bool{}
+	for _, rule := range rules.FormatRules() {
+		if seen[rule.Name()] {
+			t.Errorf("duplicate rule name %q", rule.Name())
+		}
+		seen[rule.Name()] = true
+		if rule.Description() == "" {
+			t.Errorf("rule %q has no description", rule.Name())
+		}
+	}
+}
+
+func TestRunKeepsSuppressedNodes(t *testing.T) {
+	cfg := config.DefaultConfig()
+	src := "# makefmt: disable=trim_trailing_whitespace\n" +
+		"all:\n" +
+		"\techo hi   \n" +
+		"# makefmt: enable=trim_trailing_whitespace\n" +
+		"B:=2   \n" +
+		"# makefmt: ignore-next-line\n" +
+		"C:=3\n" +
+		"\n\n\n\n"
+
+	want := "# makefmt: disable=trim_trailing_whitespace\n" +
+		"all:\n" +
+		"\techo hi   \n" +
+		"# makefmt: enable=trim_trailing_whitespace\n" +
+		"B := 2\n" +
+		"# makefmt: ignore-next-line\n" +
+		"C:=3\n"
+
+	if got := formatter.Format(src, &cfg.Formatter, rules.FormatRules()); got != want {
+		t.Errorf("want: %q\ngot:  %q", want, got)
+	}
+}
+
+func TestCheckSuppressions(t *testing.T) {
+	src := "# makefmt: disable=trim_trailing_whitspace, max_blank_lines\n" +
+		"A:=1\n" +
+		"# makefmt: enable=no_such_rule\n"
+
+	want := []formatter.Warning{
+		{Line: 1, Message: `unknown rule "trim_trailing_whitspace" in suppression comment (did you mean trim_trailing_whitespace?)`},
+		{Line: 3, Message: `unknown rule "no_such_rule" in suppression comment`},
+	}
+	got := formatter.CheckSuppressions(src, rules.FormatRules())
+	if !slices.Equal(got, want) {
+		t.Errorf("want: %v\ngot:  %v", want, got)
+	}
+}
+
+func TestFormatIndentContinuations(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.Formatter.IndentContinuations = true
+	cfg.Formatter.AlignBackslashContinuations = false
+
+	// Assignment spacing runs first and must keep the continuation lines
+	// for indent_continuations to indent.
+	src := "SRCS:=a.c \\\n\tb.c \\\n        c.c\n"
+	want := "SRCS := a.c \\\n    b.c \\\n    c.c\n"
+	if got := formatter.Format(src, &cfg.Formatter, rules.FormatRules()); got != want {
+		t.Errorf("want: %q\ngot:  %q", want, got)
+	}
+}
+
+func TestFormatAlignsWrappedConditionalAssignment(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.Formatter.MaxLineLength = 80
+
+	src := "ifdef DEBUG\nSOURCES := " + strings.Repeat("alpha.c beta.c gamma.c delta.c ", 6) + "\nendif\n"
+	got := formatter.Format(src, &cfg.Formatter, rules.FormatRules())
+
+	// Every backslash sits in backslash_column (79), including the one on
+	// the first line, which indent_conditionals shifts.
+	lines := strings.Split(got, "\n")
+	continued := 0
+	for _, line := range lines {
+		if strings.HasSuffix(line, "\\") {
+			continued++
+			if len(line) != 79 {
+				t.Errorf("backslash in column %d, want 79: %q", len(line), line)
+			}
+		}
+	}
+	if continued < 2 {
+		t.Errorf("want at least two continuation lines, got:\n%s", got)
+	}
+}
+
+func TestFormatRangeUnevenChange(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.Formatter.ListVariables = []string{"SRCS"}
+	cfg.Formatter.ListLayout = "collapse"
+	src := "SRCS := a \\\n    b\nX:=1\n"
+
+	// Collapsing the list removes a line, so the change cannot be split
+	// by line and a range touching any of it applies all of it.
+	for _, line := range []int{1, 2} {
+		edits := formatter.FormatRange(src, &cfg.Formatter, rules.FormatRules(), line, line)
+		if got, want := diff.Apply(src, edits), "SRCS := a b\nX := 1\n"; got != want {
+			t.Errorf("line %d: want %q, got %q", line, want, got)
+		}
+	}
+}
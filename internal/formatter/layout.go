@@ -0,0 +1,203 @@
+package formatter
+
+import "strings"
+
+// Doc is a layout document: text plus the places where it may break
+// across lines. Build documents with Text, Concat, Line, Group, Indent
+// and Align, and turn them into text with Render.
+type Doc interface {
+	isDoc()
+}
+
+type (
+	textDoc   string
+	concatDoc []Doc
+	groupDoc  struct{ doc Doc }
+	indentDoc struct {
+		prefix string
+		doc    Doc
+	}
+	lineDoc struct {
+		flat   string // Written when the enclosing group fits.
+		broken string // Written before the newline when it does not.
+		hard   bool   // Always breaks.
+	}
+	alignDoc struct{ col int }
+)
+
+func (textDoc) isDoc()   {}
+func (concatDoc) isDoc() {}
+func (groupDoc) isDoc()  {}
+func (indentDoc) isDoc() {}
+func (lineDoc) isDoc()   {}
+func (alignDoc) isDoc()  {}
+
+// Text returns a document holding s verbatim. Newlines in s are written
+// as is and do not pick up indentation.
+func Text(s string) Doc {
+	return textDoc(s)
+}
+
+// Concat returns the documents laid out one after another.
+func Concat(docs ...Doc) Doc {
+	return concatDoc(docs)
+}
+
+// Join returns docs with sep between each pair.
+func Join(sep Doc, docs []Doc) Doc {
+	out := make(concatDoc, 0, 2*len(docs))
+	for i, d := range docs {
+		if i > 0 {
+			out = append(out, sep)
+		}
+		out = append(out, d)
+	}
+	return out
+}
+
+// Line is a space, or a newline when its group does not fit.
+func Line() Doc {
+	return lineDoc{flat: " "}
+}
+
+// SoftLine is nothing, or a newline when its group does not fit.
+func SoftLine() Doc {
+	return lineDoc{}
+}
+
+// HardLine is always a newline.
+func HardLine() Doc {
+	return lineDoc{hard: true}
+}
+
+// Continuation is a space, or a backslash-newline when its group does not
+// fit. Make reads both as a single space between words.
+func Continuation() Doc {
+	return Break(" ", " \\")
+}
+
+// Break writes flat when its group fits, and otherwise broken followed
+// by a newline.
+func Break(flat, broken string) Doc {
+	return lineDoc{flat: flat, broken: broken}
+}
+
+// Group lays out d on one line if it fits within the render width, and
+// otherwise breaks each of its own lines. Nested groups decide for
+// themselves.
+func Group(d Doc) Doc {
+	return groupDoc{d}
+}
+
+// Indent writes prefix after every newline that a line in d breaks into.
+func Indent(prefix string, d Doc) Doc {
+	return indentDoc{prefix, d}
+}
+
+// Align pads the current line with spaces up to the 0-indexed display
+// column col, writing at least one space.
+func Align(col int) Doc {
+	return alignDoc{col}
+}
+
+// renderCmd is a document waiting on the render stack, with the
+// indentation and mode it is laid out in.
+type renderCmd struct {
+	indent string
+	flat   bool
+	doc    Doc
+}
+
+// Render lays out d, breaking groups that do not fit in width columns.
+// A width of zero or less never breaks a group. Columns are display
+// columns, with tab stops every tabWidth columns; see Advance.
+func Render(d Doc, width, tabWidth int) string {
+	var b strings.Builder
+	col := 0
+
+	stack := []renderCmd{{doc: d}}
+	for len(stack) > 0 {
+		c := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+
+		switch d := c.doc.(type) {
+		case textDoc:
+			b.WriteString(string(d))
+			col = Advance(col, string(d), tabWidth)
+
+		case concatDoc:
+			for i := len(d) - 1; i >= 0; i-- {
+				stack = append(stack, renderCmd{c.indent, c.flat, d[i]})
+			}
+
+		case indentDoc:
+			stack = append(stack, renderCmd{c.indent + d.prefix, c.flat, d.doc})
+
+		case groupDoc:
+			flat := c.flat || width <= 0 ||
+				fits(width, tabWidth, col, append(stack, renderCmd{c.indent, true, d.doc}))
+			stack = append(stack, renderCmd{c.indent, flat, d.doc})
+
+		case lineDoc:
+			if c.flat && !d.hard {
+				b.WriteString(d.flat)
+				col = Advance(col, d.flat, tabWidth)
+				continue
+			}
+			b.WriteString(d.broken)
+			b.WriteByte('\n')
+			b.WriteString(c.indent)
+			col = Width(c.indent, tabWidth)
+
+		case alignDoc:
+			pad := max(d.col-col, 1)
+			b.WriteString(strings.Repeat(" ", pad))
+			col += pad
+		}
+	}
+
+	return b.String()
+}
+
+// fits reports whether the commands on the stack, laid out from column
+// col, reach the end of the current line within width columns. The top
+// of the stack is laid out first.
+func fits(width, tabWidth, col int, stack []renderCmd) bool {
+	stack = append([]renderCmd(nil), stack...)
+
+	for len(stack) > 0 && col <= width {
+		c := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+
+		switch d := c.doc.(type) {
+		case textDoc:
+			s := string(d)
+			if i := strings.IndexByte(s, '\n'); i >= 0 {
+				return Advance(col, s[:i], tabWidth) <= width
+			}
+			col = Advance(col, s, tabWidth)
+
+		case concatDoc:
+			for i := len(d) - 1; i >= 0; i-- {
+				stack = append(stack, renderCmd{c.indent, c.flat, d[i]})
+			}
+
+		case indentDoc:
+			stack = append(stack, renderCmd{c.indent + d.prefix, c.flat, d.doc})
+
+		case groupDoc:
+			stack = append(stack, renderCmd{c.indent, c.flat, d.doc})
+
+		case lineDoc:
+			if !c.flat || d.hard {
+				return Advance(col, d.broken, tabWidth) <= width
+			}
+			col = Advance(col, d.flat, tabWidth)
+
+		case alignDoc:
+			col += max(d.col-col, 1)
+		}
+	}
+
+	return col <= width
+}
@@ -0,0 +1,63 @@
+package formatter
+
+import "testing"
+
+func TestRender(t *testing.T) {
+	list := Group(Concat(
+		Text("all:"),
+		Indent("\t", Concat(
+			Continuation(), Text("alpha"),
+			Continuation(), Text("beta"),
+			Continuation(), Text("gamma"),
+		)),
+	))
+
+	tests := []struct {
+		name  string
+		doc   Doc
+		width int
+		want  string
+	}{
+		{"text", Text("hello"), 80, "hello"},
+		{"concat", Concat(Text("a"), Text("b")), 80, "ab"},
+		{"join", Join(Text(", "), []Doc{Text("a"), Text("b"), Text("c")}), 80, "a, b, c"},
+		{"group fits", list, 80, "all: alpha beta gamma"},
+		{"group exactly fits", list, 21, "all: alpha beta gamma"},
+		{"group breaks", list, 20, "all: \\\n\talpha \\\n\tbeta \\\n\tgamma"},
+		{"unlimited width", list, 0, "all: alpha beta gamma"},
+		{
+			name:  "line and soft line",
+			doc:   Group(Concat(Text("("), SoftLine(), Text("a"), Line(), Text("b"), Text(")"))),
+			width: 3,
+			want:  "(\na\nb)",
+		},
+		{"hard line", Concat(Text("a"), HardLine(), Text("b")), 80, "a\nb"},
+		{"hard line in flat group", Group(Concat(Text("a"), HardLine(), Text("b"))), 80, "a\nb"},
+		{
+			name:  "nested group fits after outer breaks",
+			doc:   Group(Concat(Text("x"), Line(), Group(Concat(Text("y"), Line(), Text("z"))))),
+			width: 3,
+			want:  "x\ny z",
+		},
+		{
+			name:  "text after group counts",
+			doc:   Concat(Group(Concat(Text("a"), Line(), Text("b"))), Text(" # comment")),
+			width: 10,
+			want:  "a\nb # comment",
+		},
+		{"align pads", Concat(Text("ab"), Align(5), Text("#")), 80, "ab   #"},
+		{"align past column", Concat(Text("abcdef"), Align(3), Text("#")), 80, "abcdef #"},
+		{"align after tab", Concat(Text("\tab"), Align(8), Text("\\")), 80, "\tab  \\"},
+		{"align after wide text", Concat(Text("日本"), Align(6), Text("#")), 80, "日本  #"},
+		{"wide text breaks group", Group(Concat(Text("日本語"), Line(), Text("x"))), 7, "日本語\nx"},
+		{"align after newline", Concat(Text("long line\nab"), Align(4), Text("\\")), 80, "long line\nab  \\"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Render(tt.doc, tt.width, 4); got != tt.want {
+				t.Errorf("want %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
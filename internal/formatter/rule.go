@@ -10,8 +10,32 @@ type FormatRule interface {
 	// Name returns the config key for this rule (e.g., "trim_trailing_whitespace").
 	Name() string
 
+	// Description returns a one-line summary of what the rule does.
+	Description() string
+
+	// Active reports whether the rule's own settings turn it on (e.g.,
+	// trim_trailing_whitespace: true). Format does nothing when it is false.
+	Active(cfg *config.FormatterConfig) bool
+
 	// Format receives the full AST and config, returns a modified AST.
 	// Rules should not mutate the input; return new/cloned nodes where
 	// changes are needed.
 	Format(nodes []*parser.Node, cfg *config.FormatterConfig) []*parser.Node
 }
+
+// RuleNames returns the names of rules, in order.
+func RuleNames(rules []FormatRule) []string {
+	names := make([]string, len(rules))
+	for i, rule := range rules {
+		names[i] = rule.Name()
+	}
+	return names
+}
+
+// Enabled reports whether rule runs under cfg: its own settings must turn
+// it on, and formatter.rules must not turn it off. Any rule can be turned
+// off with formatter.rules; rules not listed there follow their settings.
+func Enabled(rule FormatRule, cfg *config.FormatterConfig) bool {
+	on, ok := cfg.Rules[rule.Name()]
+	return (!ok || on) && rule.Active(cfg)
+}
@@ -0,0 +1,107 @@
+package formatter
+
+import (
+	"fmt"
+
+	"github.com/donaldgifford/makefmt/internal/config"
+	"github.com/donaldgifford/makefmt/internal/parser"
+)
+
+// Warning is a problem in the source that does not stop formatting, such
+// as a suppression comment naming an unknown rule.
+type Warning struct {
+	Line    int // 1-indexed source line.
+	Message string
+}
+
+// CheckSuppressions returns a warning for each rule name in a disable= or
+// enable= suppression comment in src that names none of rules. Such
+// comments would otherwise be silently ignored.
+func CheckSuppressions(src string, rules []FormatRule) []Warning {
+	names := RuleNames(rules)
+	known := make(map[string]bool, len(names))
+	for _, name := range names {
+		known[name] = true
+	}
+
+	var warnings []Warning
+	for _, ref := range parser.FindSuppressions(parser.Parse(src)).RuleRefs() {
+		if known[ref.Name] {
+			continue
+		}
+		msg := fmt.Sprintf("unknown rule %q in suppression comment", ref.Name)
+		if s := config.Suggest(ref.Name, names); s != "" {
+			msg += fmt.Sprintf(" (did you mean %s?)", s)
+		}
+		warnings = append(warnings, Warning{Line: ref.Line, Message: msg})
+	}
+	return warnings
+}
+
+// restore undoes a rule's changes to suppressed nodes. in is the rule's
+// input and out its output; nodes are matched by source line. Suppressed
+// nodes take their input form, suppressed nodes the rule dropped are put
+// back in line order, nodes it added on suppressed lines are removed, and
+// children are restored the same way.
+func restore(in, out []*parser.Node, suppressed func(line int) bool) []*parser.Node {
+	byLine := make(map[int]*parser.Node, len(in))
+	for _, n := range in {
+		byLine[n.Line] = n
+	}
+	kept := make(map[int]bool, len(out))
+	for _, n := range out {
+		kept[n.Line] = true
+	}
+
+	var dropped []*parser.Node
+	for _, n := range in {
+		if suppressed(n.Line) && !kept[n.Line] {
+			dropped = append(dropped, n)
+		}
+	}
+
+	result := make([]*parser.Node, 0, len(out)+len(dropped))
+	for _, n := range out {
+		for len(dropped) > 0 && dropped[0].Line < n.Line {
+			result = append(result, dropped[0])
+			dropped = dropped[1:]
+		}
+
+		orig, ok := byLine[n.Line]
+		switch {
+		case !ok && suppressed(n.Line):
+			// A node the rule added for a suppressed line.
+		case !ok:
+			result = append(result, n)
+		case suppressed(n.Line):
+			result = append(result, withChildren(orig, restore(orig.Children, n.Children, suppressed)))
+		default:
+			result = append(result, withChildren(n, restore(orig.Children, n.Children, suppressed)))
+		}
+	}
+
+	return append(result, dropped...)
+}
+
+// withChildren returns n with its children replaced, copying n only when
+// the children differ.
+func withChildren(n *parser.Node, children []*parser.Node) *parser.Node {
+	if sameNodes(n.Children, children) {
+		return n
+	}
+	c := *n
+	c.Children = children
+	return &c
+}
+
+func sameNodes(a, b []*parser.Node) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,46 @@
+package formatter
+
+import "testing"
+
+func TestWidth(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		tabWidth int
+		want     int
+	}{
+		{"ascii", "hello", 4, 5},
+		{"empty", "", 4, 0},
+		{"leading tab", "\tx", 4, 5},
+		{"tab stop", "ab\tx", 4, 5},
+		{"tab at stop", "abcd\tx", 4, 9},
+		{"default tab width", "\t", 0, DefaultTabWidth},
+		{"accented", "café", 4, 4},
+		{"combining mark", "cafe\u0301", 4, 4},
+		{"zero width joiner", "a\u200db", 4, 2},
+		{"cjk", "日本語", 4, 6},
+		{"hangul", "한국", 4, 4},
+		{"fullwidth", "ＡＢ", 4, 4},
+		{"emoji", "ok 🚀", 4, 5},
+		{"cjk before tab", "日\tx", 4, 5},
+		{"newline", "long line\nab", 4, 2},
+		{"invalid utf-8", "a\xffb", 4, 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Width(tt.input, tt.tabWidth); got != tt.want {
+				t.Errorf("Width(%q, %d) = %d, want %d", tt.input, tt.tabWidth, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAdvance(t *testing.T) {
+	if got := Advance(3, "\t", 4); got != 4 {
+		t.Errorf("Advance(3, tab) = %d, want 4", got)
+	}
+	if got := Advance(2, "日", 4); got != 4 {
+		t.Errorf("Advance(2, wide) = %d, want 4", got)
+	}
+}
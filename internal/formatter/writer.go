@@ -10,133 +10,129 @@ import (
 // Write serializes an AST back into Makefile text.
 //
 // For round-trip fidelity, nodes with a non-empty Raw field emit their Raw
-// text verbatim. When a formatting rule modifies a node, it should clear or
-// update the Raw field so the writer reconstructs from parsed fields instead.
+// text verbatim. Other nodes are laid out from their parsed fields by
+// NodeDoc, so a formatting rule that changes a node's fields should clear
+// its Raw field.
 func Write(nodes []*parser.Node) string {
-	var b strings.Builder
-
+	docs := make([]Doc, 0, 2*len(nodes))
 	for _, n := range nodes {
-		writeNode(&b, n)
-		b.WriteByte('\n')
+		docs = append(docs, nodeDoc(n), HardLine())
 	}
-
-	return b.String()
+	return Render(Concat(docs...), 0, 0)
 }
 
-func writeNode(b *strings.Builder, n *parser.Node) {
-	// If Raw is set, use it for verbatim round-tripping.
+// nodeDoc returns the layout of n and its children, one per line.
+func nodeDoc(n *parser.Node) Doc {
+	docs := make([]Doc, 0, 1+2*len(n.Children))
 	if n.Raw != "" {
-		b.WriteString(n.Raw)
-		writeChildren(b, n)
-		return
+		docs = append(docs, Text(n.Raw))
+	} else {
+		docs = append(docs, NodeDoc(n))
+	}
+	for _, child := range n.Children {
+		docs = append(docs, HardLine(), nodeDoc(child))
 	}
+	return Concat(docs...)
+}
 
-	// Reconstruct from parsed fields.
-	switch n.Type {
-	case parser.NodeBlankLine:
-		// Empty line — nothing to write (the trailing \n is added by Write).
+// NodeDoc returns the layout of n's own line, built from its parsed
+// fields. Raw and children are ignored.
+func NodeDoc(n *parser.Node) Doc {
+	return Concat(ContentDoc(n), CommentDoc(n))
+}
 
-	case parser.NodeComment:
-		writeComment(b, n)
+// ContentDoc returns the layout of n's own line without its trailing
+// comment.
+func ContentDoc(n *parser.Node) Doc {
+	f := &n.Fields
 
-	case parser.NodeSectionHeader:
-		writeSectionHeader(b, n)
+	switch n.Type {
+	case parser.NodeBlankLine:
+		return Concat()
 
-	case parser.NodeBannerComment:
-		b.WriteString(n.Fields.Text)
+	case parser.NodeComment, parser.NodeSectionHeader:
+		return words(Text(f.Prefix), f.Text)
 
 	case parser.NodeAssignment:
-		writeAssignment(b, n)
+		docs := make([]Doc, 0, len(f.Modifiers)+2)
+		for _, mod := range f.Modifiers {
+			docs = append(docs, Text(mod))
+		}
+		docs = append(docs, Text(f.VarName), Text(f.AssignOp))
+		return words(Join(Text(" "), docs), f.VarValue)
 
 	case parser.NodeRule:
-		writeRule(b, n)
+		return ruleDoc(f)
 
 	case parser.NodeRecipe:
-		b.WriteByte('\t')
-		b.WriteString(n.Fields.Text)
+		return Text("\t" + f.Text)
 
 	case parser.NodeConditional:
-		writeConditional(b, n)
+		return words(Text(f.Directive), f.Condition)
 
 	case parser.NodeInclude:
-		writeInclude(b, n)
+		return words(Text(f.IncludeType), f.Paths...)
 
-	case parser.NodeDirective:
-		b.WriteString(n.Fields.Text)
+	case parser.NodeExport:
+		return words(Text(f.Directive), f.Names...)
 
-	case parser.NodeRaw:
-		// Should be handled by Raw check above; fallback.
-		b.WriteString(n.Fields.Text)
+	default:
+		// Banners, directives and raw lines keep their text.
+		return Text(f.Text)
 	}
-
-	writeChildren(b, n)
 }
 
-func writeChildren(b *strings.Builder, n *parser.Node) {
-	for _, child := range n.Children {
-		b.WriteByte('\n')
-		writeNode(b, child)
+// CommentDoc returns n's trailing comment with the whitespace before it,
+// or a single space when none was recorded.
+func CommentDoc(n *parser.Node) Doc {
+	if n.Fields.InlineComment == "" {
+		return Concat()
 	}
-}
-
-func writeComment(b *strings.Builder, n *parser.Node) {
-	b.WriteString(n.Fields.Prefix)
-	if n.Fields.Text != "" {
-		b.WriteByte(' ')
-		b.WriteString(n.Fields.Text)
+	space := n.Fields.CommentSpace
+	if space == "" {
+		space = " "
 	}
+	return Text(space + n.Fields.InlineComment)
 }
 
-func writeSectionHeader(b *strings.Builder, n *parser.Node) {
-	b.WriteString(n.Fields.Prefix)
-	if n.Fields.Text != "" {
-		b.WriteByte(' ')
-		b.WriteString(n.Fields.Text)
-	}
-}
+// ruleDoc lays out a rule line: targets, prerequisites, order-only
+// prerequisites, then an inline recipe or help text.
+func ruleDoc(f *parser.NodeFields) Doc {
+	docs := []Doc{Text(strings.Join(f.Targets, " ") + RuleSeparator(f))}
 
-func writeAssignment(b *strings.Builder, n *parser.Node) {
-	b.WriteString(n.Fields.VarName)
-	b.WriteByte(' ')
-	b.WriteString(n.Fields.AssignOp)
-	if n.Fields.VarValue != "" {
-		b.WriteByte(' ')
-		b.WriteString(n.Fields.VarValue)
+	if len(f.Prerequisites) > 0 {
+		docs = append(docs, words(Concat(), f.Prerequisites...))
 	}
-}
-
-func writeRule(b *strings.Builder, n *parser.Node) {
-	b.WriteString(strings.Join(n.Fields.Targets, " "))
-	b.WriteByte(':')
-
-	if len(n.Fields.Prerequisites) > 0 {
-		b.WriteByte(' ')
-		b.WriteString(strings.Join(n.Fields.Prerequisites, " "))
+	if len(f.OrderOnly) > 0 {
+		docs = append(docs, words(Text(" |"), f.OrderOnly...))
 	}
-
-	if len(n.Fields.OrderOnly) > 0 {
-		b.WriteString(" | ")
-		b.WriteString(strings.Join(n.Fields.OrderOnly, " "))
+	if f.InlineRecipe != "" {
+		docs = append(docs, Text(" ; "+f.InlineRecipe))
 	}
-
-	if n.Fields.InlineHelp != "" {
-		b.WriteString(" ## ")
-		b.WriteString(n.Fields.InlineHelp)
+	if f.InlineHelp != "" {
+		docs = append(docs, Text(" ## "+f.InlineHelp))
 	}
+
+	return Concat(docs...)
 }
 
-func writeConditional(b *strings.Builder, n *parser.Node) {
-	b.WriteString(n.Fields.Directive)
-	if n.Fields.Condition != "" {
-		b.WriteByte(' ')
-		b.WriteString(n.Fields.Condition)
+// RuleSeparator returns the colon or double colon that ends a rule's
+// targets.
+func RuleSeparator(f *parser.NodeFields) string {
+	if f.DoubleColon {
+		return "::"
 	}
+	return ":"
 }
 
-func writeInclude(b *strings.Builder, n *parser.Node) {
-	b.WriteString(n.Fields.IncludeType)
-	if len(n.Fields.Paths) > 0 {
-		b.WriteByte(' ')
-		b.WriteString(strings.Join(n.Fields.Paths, " "))
+// words returns head followed by each non-empty word, separated by
+// single spaces.
+func words(head Doc, ws ...string) Doc {
+	docs := []Doc{head}
+	for _, w := range ws {
+		if w != "" {
+			docs = append(docs, Text(" "+w))
+		}
 	}
+	return Concat(docs...)
 }
@@ -112,6 +112,57 @@ func TestWriteReconstructsFromFields(t *testing.T) {
 			},
 			expected: "FOO := bar\n",
 		},
+		{
+			name: "assignment with modifiers",
+			node: &parser.Node{
+				Type: parser.NodeAssignment,
+				Fields: parser.NodeFields{
+					Modifiers: []string{"export", "override"},
+					VarName:   "FOO",
+					AssignOp:  "+=",
+					VarValue:  "bar",
+				},
+			},
+			expected: "export override FOO += bar\n",
+		},
+		{
+			name: "export from fields",
+			node: &parser.Node{
+				Type: parser.NodeExport,
+				Fields: parser.NodeFields{
+					Directive: "unexport",
+					Names:     []string{"A", "B"},
+				},
+			},
+			expected: "unexport A B\n",
+		},
+		{
+			name: "rule with inline recipe",
+			node: &parser.Node{
+				Type: parser.NodeRule,
+				Fields: parser.NodeFields{
+					Targets:       []string{"all"},
+					Prerequisites: []string{"a"},
+					OrderOnly:     []string{"dir"},
+					InlineRecipe:  "@echo done",
+				},
+			},
+			expected: "all: a | dir ; @echo done\n",
+		},
+		{
+			name: "assignment with inline comment",
+			node: &parser.Node{
+				Type: parser.NodeAssignment,
+				Fields: parser.NodeFields{
+					VarName:       "FOO",
+					AssignOp:      ":=",
+					VarValue:      "bar",
+					InlineComment: "# note",
+					CommentSpace:  "   ",
+				},
+			},
+			expected: "FOO := bar   # note\n",
+		},
 		{
 			name: "assignment empty value",
 			node: &parser.Node{
@@ -135,6 +186,19 @@ func TestWriteReconstructsFromFields(t *testing.T) {
 			},
 			expected: "build: main.go ## Build it\n",
 		},
+		{
+			name: "double-colon rule from fields",
+			node: &parser.Node{
+				Type: parser.NodeRule,
+				Fields: parser.NodeFields{
+					Targets:       []string{"y"},
+					DoubleColon:   true,
+					Prerequisites: []string{"a"},
+					OrderOnly:     []string{"out"},
+				},
+			},
+			expected: "y:: a | out\n",
+		},
 		{
 			name: "blank line from fields",
 			node: &parser.Node{
@@ -0,0 +1,189 @@
+// Package git queries a git working tree for changed files and lines.
+//
+// All queries shell out to the git binary found on PATH, so makefmt does
+// not need to understand git's object database itself.
+package git
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/donaldgifford/makefmt/pkg/diff"
+)
+
+// WholeFile is the range returned for files git has no history for, such
+// as untracked files. It covers every line.
+var WholeFile = diff.LineRange{Start: 1, End: math.MaxInt}
+
+// ChangedFiles returns the files that differ between ref and the working
+// tree, including untracked files. Paths are relative to the current
+// working directory. Deleted files are omitted.
+func ChangedFiles(ref string) ([]string, error) {
+	changed, err := run("diff", "--name-only", "--diff-filter=ACMR", ref, "--")
+	if err != nil {
+		return nil, err
+	}
+	untracked, err := run("ls-files", "--others", "--exclude-standard", "--full-name")
+	if err != nil {
+		return nil, err
+	}
+	return relativize(append(splitPaths(changed), splitPaths(untracked)...))
+}
+
+// StagedFiles returns the files with changes staged in the index. Paths
+// are relative to the current working directory. Deleted files are omitted.
+func StagedFiles() ([]string, error) {
+	out, err := run("diff", "--cached", "--name-only", "--diff-filter=ACMR")
+	if err != nil {
+		return nil, err
+	}
+	return relativize(splitPaths(out))
+}
+
+// ChangedLines returns the lines of path's working tree content that
+// differ from ref. Untracked files report WholeFile.
+func ChangedLines(ref, path string) ([]diff.LineRange, error) {
+	if !isTracked(path) {
+		return []diff.LineRange{WholeFile}, nil
+	}
+
+	out, err := run("diff", "--unified=0", "--no-color", "--no-ext-diff", ref, "--", path)
+	if err != nil {
+		return nil, err
+	}
+	return ParseHunkRanges(out)
+}
+
+// StagedLines returns the lines of path's staged content that differ from
+// HEAD. The line numbers match the working tree when path has no unstaged
+// changes, as in a pre-commit hook.
+func StagedLines(path string) ([]diff.LineRange, error) {
+	out, err := run("diff", "--cached", "--unified=0", "--no-color", "--no-ext-diff", "--", path)
+	if err != nil {
+		return nil, err
+	}
+	return ParseHunkRanges(out)
+}
+
+// ParseHunkRanges extracts the new-side line ranges from the hunk headers
+// of a unified diff. A hunk that only deletes lines yields the range of
+// the lines on either side of the deletion.
+func ParseHunkRanges(unified []byte) ([]diff.LineRange, error) {
+	var ranges []diff.LineRange
+
+	scanner := bufio.NewScanner(bytes.NewReader(unified))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if !strings.HasPrefix(line, "@@ ") {
+			continue
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) < 3 || !strings.HasPrefix(fields[2], "+") {
+			return nil, fmt.Errorf("malformed hunk header: %q", line)
+		}
+
+		start, count, err := parseHunkSide(fields[2][1:])
+		if err != nil {
+			return nil, fmt.Errorf("malformed hunk header %q: %w", line, err)
+		}
+
+		if count == 0 {
+			ranges = append(ranges, diff.LineRange{Start: max(start, 1), End: start + 1})
+			continue
+		}
+		ranges = append(ranges, diff.LineRange{Start: start, End: start + count - 1})
+	}
+
+	return ranges, scanner.Err()
+}
+
+// parseHunkSide parses "start[,count]" from a hunk header.
+func parseHunkSide(s string) (start, count int, err error) {
+	startStr, countStr, found := strings.Cut(s, ",")
+	start, err = strconv.Atoi(startStr)
+	if err != nil {
+		return 0, 0, err
+	}
+	if !found {
+		return start, 1, nil
+	}
+	count, err = strconv.Atoi(countStr)
+	if err != nil {
+		return 0, 0, err
+	}
+	return start, count, nil
+}
+
+func isTracked(path string) bool {
+	_, err := run("ls-files", "--error-unmatch", "--", path)
+	return err == nil
+}
+
+// relativize converts repository-relative paths to paths relative to the
+// current working directory.
+func relativize(paths []string) ([]string, error) {
+	if len(paths) == 0 {
+		return nil, nil
+	}
+
+	out, err := run("rev-parse", "--show-toplevel")
+	if err != nil {
+		return nil, err
+	}
+	top := strings.TrimSpace(string(out))
+
+	wd, err := os.Getwd()
+	if err != nil {
+		return nil, fmt.Errorf("getting working directory: %w", err)
+	}
+
+	result := make([]string, 0, len(paths))
+	for _, p := range paths {
+		abs := filepath.Join(top, filepath.FromSlash(p))
+		rel, err := filepath.Rel(wd, abs)
+		if err != nil {
+			rel = abs
+		}
+		result = append(result, rel)
+	}
+	return result, nil
+}
+
+func splitPaths(out []byte) []string {
+	var paths []string
+	for line := range strings.SplitSeq(string(out), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			paths = append(paths, line)
+		}
+	}
+	return paths
+}
+
+// run executes git with the given arguments and returns its stdout.
+func run(args ...string) ([]byte, error) {
+	var stderr bytes.Buffer
+	cmd := exec.Command("git", args...)
+	cmd.Stderr = &stderr
+
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("git %s: %s", args[0], msg)
+		}
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return nil, fmt.Errorf("git %s: exit status %d", args[0], exitErr.ExitCode())
+		}
+		return nil, fmt.Errorf("git %s: %w", args[0], err)
+	}
+	return out, nil
+}
@@ -0,0 +1,116 @@
+package git
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/donaldgifford/makefmt/internal/testutil"
+	"github.com/donaldgifford/makefmt/pkg/diff"
+)
+
+func TestParseHunkRanges(t *testing.T) {
+	unified := `diff --git a/Makefile b/Makefile
+index 1111111..2222222 100644
+--- a/Makefile
++++ b/Makefile
+@@ -3 +3 @@ all:
+-VAR:=1
++VAR:=2
+@@ -10,0 +11,2 @@ build:
++A := 1
++B := 2
+@@ -20,2 +21,0 @@
+-old
+-old
+`
+
+	got, err := ParseHunkRanges([]byte(unified))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []diff.LineRange{{Start: 3, End: 3}, {Start: 11, End: 12}, {Start: 21, End: 22}}
+	if !slices.Equal(got, want) {
+		t.Errorf("ParseHunkRanges = %v, want %v", got, want)
+	}
+}
+
+func TestParseHunkRangesMalformed(t *testing.T) {
+	if _, err := ParseHunkRanges([]byte("@@ -1 +x @@\n")); err == nil {
+		t.Error("expected error for malformed hunk header")
+	}
+}
+
+func TestRepositoryQueries(t *testing.T) {
+	dir := testutil.InitRepo(t)
+
+	testutil.WriteFile(t, dir, "Makefile", "A:=1\nB:=2\nC:=3\n")
+	testutil.WriteFile(t, dir, "other.mk", "X:=1\n")
+	testutil.Git(t, dir, "add", ".")
+	testutil.Git(t, dir, "commit", "-q", "-m", "initial")
+
+	testutil.WriteFile(t, dir, "Makefile", "A:=1\nB:=changed\nC:=3\n")
+	testutil.WriteFile(t, dir, "new.mk", "Y:=1\n")
+	testutil.WriteFile(t, dir, "staged.mk", "Z:=1\n")
+	testutil.Git(t, dir, "add", "staged.mk")
+
+	t.Chdir(dir)
+
+	changed, err := ChangedFiles("HEAD")
+	if err != nil {
+		t.Fatal(err)
+	}
+	slices.Sort(changed)
+	if want := []string{"Makefile", "new.mk", "staged.mk"}; !slices.Equal(changed, want) {
+		t.Errorf("ChangedFiles = %v, want %v", changed, want)
+	}
+
+	staged, err := StagedFiles()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"staged.mk"}; !slices.Equal(staged, want) {
+		t.Errorf("StagedFiles = %v, want %v", staged, want)
+	}
+
+	lines, err := ChangedLines("HEAD", "Makefile")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []diff.LineRange{{Start: 2, End: 2}}; !slices.Equal(lines, want) {
+		t.Errorf("ChangedLines(Makefile) = %v, want %v", lines, want)
+	}
+
+	lines, err = StagedLines("staged.mk")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []diff.LineRange{{Start: 1, End: 1}}; !slices.Equal(lines, want) {
+		t.Errorf("StagedLines(staged.mk) = %v, want %v", lines, want)
+	}
+
+	lines, err = StagedLines("Makefile")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(lines) != 0 {
+		t.Errorf("StagedLines(Makefile) = %v, want none for unstaged changes", lines)
+	}
+
+	lines, err = ChangedLines("HEAD", "new.mk")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []diff.LineRange{WholeFile}; !slices.Equal(lines, want) {
+		t.Errorf("ChangedLines(new.mk) = %v, want %v", lines, want)
+	}
+}
+
+func TestChangedFilesBadRef(t *testing.T) {
+	dir := testutil.InitRepo(t)
+	t.Chdir(dir)
+
+	if _, err := ChangedFiles("no-such-ref"); err == nil {
+		t.Error("expected error for unknown ref")
+	}
+}
@@ -0,0 +1,227 @@
+package lsp
+
+import (
+	"fmt"
+
+	"github.com/donaldgifford/makefmt/internal/formatter"
+	"github.com/donaldgifford/makefmt/internal/parser"
+	"github.com/donaldgifford/makefmt/pkg/diff"
+)
+
+// diagnosticCode identifies formatting diagnostics and their quick fixes.
+const diagnosticCode = "format"
+
+// suppressCode identifies diagnostics for suppression comments that name
+// unknown rules. They have no quick fix.
+const suppressCode = "suppress"
+
+func (s *Server) didOpen(p *DidOpenTextDocumentParams) (any, error) {
+	s.docs[p.TextDocument.URI] = p.TextDocument.Text
+	return nil, s.publishDiagnostics(p.TextDocument.URI, &p.TextDocument.Version)
+}
+
+func (s *Server) didChange(p *DidChangeTextDocumentParams) (any, error) {
+	if len(p.ContentChanges) == 0 {
+		return nil, nil
+	}
+	// Full sync: the last change holds the whole document.
+	s.docs[p.TextDocument.URI] = p.ContentChanges[len(p.ContentChanges)-1].Text
+	return nil, s.publishDiagnostics(p.TextDocument.URI, &p.TextDocument.Version)
+}
+
+func (s *Server) didClose(p *DidCloseTextDocumentParams) (any, error) {
+	delete(s.docs, p.TextDocument.URI)
+	return nil, s.conn.notify("textDocument/publishDiagnostics", &PublishDiagnosticsParams{
+		URI:         p.TextDocument.URI,
+		Diagnostics: []Diagnostic{},
+	})
+}
+
+// publishDiagnostics reports one diagnostic per formatting edit the
+// document needs, and one per unknown rule named in a suppression comment.
+func (s *Server) publishDiagnostics(uri string, version *int) error {
+	text, output, err := s.formatted(uri)
+	if err != nil {
+		return err
+	}
+
+	doc := newDocument(text)
+	diagnostics := []Diagnostic{}
+	for _, e := range diff.Edits(text, output) {
+		diagnostics = append(diagnostics, Diagnostic{
+			Range:    Range{Start: doc.position(e.Start), End: doc.position(e.End)},
+			Severity: SeverityWarning,
+			Code:     diagnosticCode,
+			Source:   "makefmt",
+			Message:  describeEdit(text[e.Start:e.End], e.NewText),
+		})
+	}
+	for _, w := range formatter.CheckSuppressions(text, s.Rules) {
+		diagnostics = append(diagnostics, Diagnostic{
+			Range:    Range{Start: Position{Line: w.Line - 1}, End: Position{Line: w.Line}},
+			Severity: SeverityWarning,
+			Code:     suppressCode,
+			Source:   "makefmt",
+			Message:  w.Message,
+		})
+	}
+
+	return s.conn.notify("textDocument/publishDiagnostics", &PublishDiagnosticsParams{
+		URI:         uri,
+		Version:     version,
+		Diagnostics: diagnostics,
+	})
+}
+
+// describeEdit returns a human-readable message for a formatting edit.
+func describeEdit(old, replacement string) string {
+	switch {
+	case old == "":
+		return fmt.Sprintf("not formatted: insert %q", replacement)
+	case replacement == "":
+		return fmt.Sprintf("not formatted: remove %q", old)
+	default:
+		return fmt.Sprintf("not formatted: replace %q with %q", old, replacement)
+	}
+}
+
+func (s *Server) formatting(p *DocumentFormattingParams) (any, error) {
+	text, output, err := s.formatted(p.TextDocument.URI)
+	if err != nil {
+		return nil, err
+	}
+	return newDocument(text).textEdits(diff.Edits(text, output)), nil
+}
+
+func (s *Server) rangeFormatting(p *DocumentRangeFormattingParams) (any, error) {
+	text, err := s.text(p.TextDocument.URI)
+	if err != nil {
+		return nil, err
+	}
+	cfg, err := s.formatterConfig(p.TextDocument.URI)
+	if err != nil {
+		return nil, fmt.Errorf("loading config: %w", err)
+	}
+
+	// A selection ending at the start of a line does not include that line.
+	endLine := p.Range.End.Line
+	if p.Range.End.Character == 0 && endLine > p.Range.Start.Line {
+		endLine--
+	}
+
+	edits := formatter.FormatRange(text, cfg, s.Rules, p.Range.Start.Line+1, endLine+1)
+	return newDocument(text).textEdits(edits), nil
+}
+
+// onTypeFormatting formats a rule line once the user presses Enter after
+// it. Other lines are left alone so typing is not disrupted.
+func (s *Server) onTypeFormatting(p *DocumentOnTypeFormattingParams) (any, error) {
+	if p.Ch != "\n" || p.Position.Line == 0 {
+		return []TextEdit{}, nil
+	}
+
+	text, err := s.text(p.TextDocument.URI)
+	if err != nil {
+		return nil, err
+	}
+	cfg, err := s.formatterConfig(p.TextDocument.URI)
+	if err != nil {
+		return nil, fmt.Errorf("loading config: %w", err)
+	}
+
+	// The completed line, 1-indexed, is the one before the cursor.
+	line := p.Position.Line
+	if !isRuleLine(parser.Parse(text), line) {
+		return []TextEdit{}, nil
+	}
+
+	edits := formatter.FormatRange(text, cfg, s.Rules, line, line)
+	return newDocument(text).textEdits(edits), nil
+}
+
+// isRuleLine reports whether the 1-indexed line starts a rule.
+func isRuleLine(nodes []*parser.Node, line int) bool {
+	for _, n := range nodes {
+		if n.Line == line {
+			return n.Type == parser.NodeRule
+		}
+	}
+	return false
+}
+
+// codeAction offers a quick fix for each formatting diagnostic in the
+// requested range and a fix-all action for the whole document.
+func (s *Server) codeAction(p *CodeActionParams) (any, error) {
+	text, output, err := s.formatted(p.TextDocument.URI)
+	if err != nil {
+		return nil, err
+	}
+
+	doc := newDocument(text)
+	uri := p.TextDocument.URI
+	edits := doc.textEdits(diff.Edits(text, output))
+	actions := []CodeAction{}
+	if len(edits) == 0 {
+		return actions, nil
+	}
+
+	if wantKind(p.Context.Only, CodeActionQuickFix) {
+		for _, e := range edits {
+			if !rangesOverlap(e.Range, p.Range) {
+				continue
+			}
+			actions = append(actions, CodeAction{
+				Title:       "Fix formatting",
+				Kind:        CodeActionQuickFix,
+				Diagnostics: matchingDiagnostics(p.Context.Diagnostics, e.Range),
+				IsPreferred: true,
+				Edit:        WorkspaceEdit{Changes: map[string][]TextEdit{uri: {e}}},
+			})
+		}
+	}
+
+	if wantKind(p.Context.Only, CodeActionSourceFixAll) {
+		actions = append(actions, CodeAction{
+			Title: "Format document with makefmt",
+			Kind:  CodeActionSourceFixAll,
+			Edit:  WorkspaceEdit{Changes: map[string][]TextEdit{uri: edits}},
+		})
+	}
+
+	return actions, nil
+}
+
+// wantKind reports whether kind passes the client's "only" filter. A
+// filter entry matches kinds it is a dot-separated prefix of.
+func wantKind(only []string, kind string) bool {
+	if len(only) == 0 {
+		return true
+	}
+	for _, o := range only {
+		if o == kind || (len(kind) > len(o) && kind[:len(o)] == o && kind[len(o)] == '.') {
+			return true
+		}
+	}
+	return false
+}
+
+func matchingDiagnostics(diagnostics []Diagnostic, r Range) []Diagnostic {
+	var matched []Diagnostic
+	for _, d := range diagnostics {
+		if d.Code == diagnosticCode && d.Range == r {
+			matched = append(matched, d)
+		}
+	}
+	return matched
+}
+
+// rangesOverlap reports whether a and b intersect. Touching ranges and
+// empty ranges at a boundary count as overlapping, so an insertion point
+// under the cursor is found.
+func rangesOverlap(a, b Range) bool {
+	return !positionBefore(a.End, b.Start) && !positionBefore(b.End, a.Start)
+}
+
+func positionBefore(a, b Position) bool {
+	return a.Line < b.Line || (a.Line == b.Line && a.Character < b.Character)
+}
@@ -0,0 +1,100 @@
+package lsp
+
+import (
+	"unicode/utf8"
+
+	"github.com/donaldgifford/makefmt/pkg/diff"
+)
+
+// document maps between byte offsets and LSP positions for one text.
+type document struct {
+	text       string
+	lineStarts []int // Byte offset at which each line starts.
+}
+
+func newDocument(text string) *document {
+	starts := []int{0}
+	for i := 0; i < len(text); i++ {
+		if text[i] == '\n' {
+			starts = append(starts, i+1)
+		}
+	}
+	return &document{text: text, lineStarts: starts}
+}
+
+// position converts a byte offset into a zero-based line and UTF-16
+// character offset.
+func (d *document) position(offset int) Position {
+	offset = min(max(offset, 0), len(d.text))
+
+	line := 0
+	for line+1 < len(d.lineStarts) && d.lineStarts[line+1] <= offset {
+		line++
+	}
+	return Position{Line: line, Character: utf16Len(d.text[d.lineStarts[line]:offset])}
+}
+
+// offset converts an LSP position into a byte offset, clamping positions
+// past the end of a line or the document.
+func (d *document) offset(pos Position) int {
+	if pos.Line < 0 {
+		return 0
+	}
+	if pos.Line >= len(d.lineStarts) {
+		return len(d.text)
+	}
+
+	start := d.lineStarts[pos.Line]
+	end := len(d.text)
+	if pos.Line+1 < len(d.lineStarts) {
+		end = d.lineStarts[pos.Line+1] - 1 // Exclude the newline.
+	}
+
+	units := 0
+	for i, r := range d.text[start:end] {
+		if units >= pos.Character {
+			return start + i
+		}
+		units += utf16RuneLen(r)
+	}
+	return end
+}
+
+// lineRange returns the range covering the text of the given zero-based
+// line, excluding its newline.
+func (d *document) lineRange(line int) Range {
+	start := d.offset(Position{Line: line})
+	end := len(d.text)
+	if line+1 < len(d.lineStarts) {
+		end = d.lineStarts[line+1] - 1
+	}
+	return Range{Start: d.position(start), End: d.position(end)}
+}
+
+// textEdits converts byte-offset edits into LSP text edits.
+func (d *document) textEdits(edits []diff.Edit) []TextEdit {
+	result := make([]TextEdit, 0, len(edits))
+	for _, e := range edits {
+		result = append(result, TextEdit{
+			Range:   Range{Start: d.position(e.Start), End: d.position(e.End)},
+			NewText: e.NewText,
+		})
+	}
+	return result
+}
+
+func utf16Len(s string) int {
+	n := 0
+	for _, r := range s {
+		n += utf16RuneLen(r)
+	}
+	return n
+}
+
+// utf16RuneLen returns the number of UTF-16 code units needed for r.
+func utf16RuneLen(r rune) int {
+	if r >= 0x10000 && r <= utf8.MaxRune {
+		return 2
+	}
+	return 1
+}
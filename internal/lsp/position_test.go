@@ -0,0 +1,42 @@
+package lsp
+
+import "testing"
+
+func TestDocumentPositionOffset(t *testing.T) {
+	// "é" is two bytes and one UTF-16 unit; "😀" is four bytes and two
+	// UTF-16 units.
+	doc := newDocument("ab\né😀x\n")
+
+	tests := []struct {
+		offset int
+		pos    Position
+	}{
+		{0, Position{Line: 0, Character: 0}},
+		{2, Position{Line: 0, Character: 2}},
+		{3, Position{Line: 1, Character: 0}},
+		{5, Position{Line: 1, Character: 1}},
+		{9, Position{Line: 1, Character: 3}},
+		{10, Position{Line: 1, Character: 4}},
+		{11, Position{Line: 2, Character: 0}},
+	}
+
+	for _, tt := range tests {
+		if got := doc.position(tt.offset); got != tt.pos {
+			t.Errorf("position(%d) = %+v, want %+v", tt.offset, got, tt.pos)
+		}
+		if got := doc.offset(tt.pos); got != tt.offset {
+			t.Errorf("offset(%+v) = %d, want %d", tt.pos, got, tt.offset)
+		}
+	}
+}
+
+func TestDocumentOffsetClamps(t *testing.T) {
+	doc := newDocument("ab\ncd\n")
+
+	if got := doc.offset(Position{Line: 0, Character: 99}); got != 2 {
+		t.Errorf("past end of line: got %d, want 2", got)
+	}
+	if got := doc.offset(Position{Line: 99}); got != 6 {
+		t.Errorf("past end of document: got %d, want 6", got)
+	}
+}
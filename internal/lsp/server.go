@@ -0,0 +1,245 @@
+// Package lsp implements a Language Server Protocol server for makefmt.
+//
+// The server speaks JSON-RPC over a pair of streams (normally stdin and
+// stdout) and exposes document, range, and on-type formatting, formatting
+// diagnostics with quick fixes, and document symbols for targets,
+// variables, and ##@ sections.
+package lsp
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"path/filepath"
+
+	"github.com/donaldgifford/makefmt/internal/config"
+	"github.com/donaldgifford/makefmt/internal/formatter"
+)
+
+// ErrExitWithoutShutdown is returned by Serve when the client sends exit
+// before shutdown.
+var ErrExitWithoutShutdown = errors.New("exit received before shutdown")
+
+// Server is a makefmt language server.
+type Server struct {
+	// Rules are the formatting rules applied to documents.
+	Rules []formatter.FormatRule
+
+	// ConfigPath, if set, is used for every document instead of config
+	// discovery.
+	ConfigPath string
+
+	// Settings override individual config values for every document.
+	Settings []config.Setting
+
+	// LoadConfig returns the config for the file at path. Path is empty
+	// for documents that are not files. By default, configs are resolved
+	// as for the CLI, using ConfigPath and Settings.
+	LoadConfig func(path string) (*config.Config, error)
+
+	// Version is reported to the client in serverInfo.
+	Version string
+
+	conn        *conn
+	docs        map[string]string
+	initialized bool
+	shutdown    bool
+}
+
+// errExit stops the message loop after an exit notification.
+var errExit = errors.New("exit")
+
+// Serve reads requests from r and writes responses to w until the client
+// sends exit or r is closed.
+func (s *Server) Serve(r io.Reader, w io.Writer) error {
+	s.conn = newConn(r, w)
+	s.docs = make(map[string]string)
+	if s.LoadConfig == nil {
+		s.LoadConfig = s.discoverConfig
+	}
+
+	for {
+		msg, err := s.conn.read()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			var rpcErr *responseError
+			if errors.As(err, &rpcErr) {
+				if err := s.conn.replyError(nil, rpcErr.Code, rpcErr.Message); err != nil {
+					return err
+				}
+				continue
+			}
+			return err
+		}
+
+		if err := s.handle(msg); err != nil {
+			if errors.Is(err, errExit) {
+				if !s.shutdown {
+					return ErrExitWithoutShutdown
+				}
+				return nil
+			}
+			return err
+		}
+	}
+}
+
+// handle dispatches one message. Errors returned from handle are fatal
+// transport errors; request failures are sent back as error responses.
+func (s *Server) handle(msg *message) error {
+	if msg.Method == "exit" {
+		return errExit
+	}
+
+	// Responses to server-initiated requests are not expected; ignore them.
+	if msg.Method == "" {
+		return nil
+	}
+
+	result, rpcErr := s.dispatch(msg)
+
+	if msg.ID == nil {
+		// Notifications never receive a response.
+		return nil
+	}
+	if rpcErr != nil {
+		return s.conn.replyError(msg.ID, rpcErr.Code, rpcErr.Message)
+	}
+	return s.conn.reply(msg.ID, result)
+}
+
+// dispatch runs the handler for msg.Method.
+func (s *Server) dispatch(msg *message) (any, *responseError) {
+	if msg.Method == "initialize" {
+		s.initialized = true
+		return s.initializeResult(), nil
+	}
+	if !s.initialized {
+		return nil, &responseError{Code: codeServerNotInitialized, Message: "server not initialized"}
+	}
+	if s.shutdown {
+		return nil, &responseError{Code: codeInvalidRequest, Message: "server is shutting down"}
+	}
+
+	switch msg.Method {
+	case "initialized", "$/cancelRequest", "$/setTrace", "workspace/didChangeConfiguration":
+		return nil, nil
+	case "shutdown":
+		s.shutdown = true
+		return nil, nil
+	case "textDocument/didOpen":
+		return handleParams(msg, s.didOpen)
+	case "textDocument/didChange":
+		return handleParams(msg, s.didChange)
+	case "textDocument/didClose":
+		return handleParams(msg, s.didClose)
+	case "textDocument/formatting":
+		return handleParams(msg, s.formatting)
+	case "textDocument/rangeFormatting":
+		return handleParams(msg, s.rangeFormatting)
+	case "textDocument/onTypeFormatting":
+		return handleParams(msg, s.onTypeFormatting)
+	case "textDocument/documentSymbol":
+		return handleParams(msg, s.documentSymbol)
+	case "textDocument/codeAction":
+		return handleParams(msg, s.codeAction)
+	}
+
+	return nil, &responseError{Code: codeMethodNotFound, Message: "method not found: " + msg.Method}
+}
+
+// handleParams decodes msg.Params into P and calls fn.
+func handleParams[P any](msg *message, fn func(*P) (any, error)) (any, *responseError) {
+	var params P
+	if err := json.Unmarshal(msg.Params, &params); err != nil {
+		return nil, &responseError{Code: codeInvalidParams, Message: err.Error()}
+	}
+
+	result, err := fn(&params)
+	if err != nil {
+		var rpcErr *responseError
+		if errors.As(err, &rpcErr) {
+			return nil, rpcErr
+		}
+		return nil, &responseError{Code: codeInternalError, Message: err.Error()}
+	}
+	return result, nil
+}
+
+func (s *Server) initializeResult() *InitializeResult {
+	return &InitializeResult{
+		Capabilities: ServerCapabilities{
+			TextDocumentSync:                textDocumentSyncFull,
+			DocumentFormattingProvider:      true,
+			DocumentRangeFormattingProvider: true,
+			DocumentOnTypeFormattingProvider: &DocumentOnTypeFormattingOptions{
+				FirstTriggerCharacter: "\n",
+			},
+			DocumentSymbolProvider: true,
+			CodeActionProvider: &CodeActionOptions{
+				CodeActionKinds: []string{CodeActionQuickFix, CodeActionSourceFixAll},
+			},
+		},
+		ServerInfo: ServerInfo{Name: "makefmt", Version: s.Version},
+	}
+}
+
+// text returns the content of an open document.
+func (s *Server) text(uri string) (string, error) {
+	text, ok := s.docs[uri]
+	if !ok {
+		return "", &responseError{Code: codeInvalidParams, Message: "document not open: " + uri}
+	}
+	return text, nil
+}
+
+// formatterConfig returns the formatter config for the document at uri.
+func (s *Server) formatterConfig(uri string) (*config.FormatterConfig, error) {
+	cfg, err := s.LoadConfig(uriPath(uri))
+	if err != nil {
+		return nil, err
+	}
+	return &cfg.Formatter, nil
+}
+
+// discoverConfig loads the config that applies to the file at path.
+// Documents that are not files use the config for the server's working
+// directory. Configs are not cached, so edits to config files apply to
+// the next request.
+func (s *Server) discoverConfig(path string) (*config.Config, error) {
+	r := config.NewResolver(s.ConfigPath, formatter.RuleNames(s.Rules))
+	if err := r.Set(s.Settings...); err != nil {
+		return nil, err
+	}
+	if path == "" {
+		return r.ForDir(".")
+	}
+	return r.ForFile(path)
+}
+
+// uriPath returns the file system path of a file:// URI, or an empty
+// string for other schemes.
+func uriPath(uri string) string {
+	u, err := url.Parse(uri)
+	if err != nil || u.Scheme != "file" {
+		return ""
+	}
+	return filepath.FromSlash(u.Path)
+}
+
+// formatted returns the document text and its formatted form.
+func (s *Server) formatted(uri string) (text, output string, err error) {
+	text, err = s.text(uri)
+	if err != nil {
+		return "", "", err
+	}
+	cfg, err := s.formatterConfig(uri)
+	if err != nil {
+		return "", "", fmt.Errorf("loading config: %w", err)
+	}
+	return text, formatter.Format(text, cfg, s.Rules), nil
+}
@@ -0,0 +1,465 @@
+package lsp
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"strconv"
+	"testing"
+
+	"github.com/donaldgifford/makefmt/internal/config"
+	"github.com/donaldgifford/makefmt/internal/rules"
+	"github.com/donaldgifford/makefmt/pkg/diff"
+)
+
+const testURI = "file:///project/Makefile"
+
+// client is an in-process JSON-RPC client connected to a Server.
+type client struct {
+	t        *testing.T
+	conn     *conn
+	nextID   int
+	incoming chan *message
+	done     chan error
+
+	// notifications holds server notifications received while waiting
+	// for responses.
+	notifications []*message
+}
+
+func newClient(t *testing.T) *client {
+	t.Helper()
+
+	clientReader, serverWriter := io.Pipe()
+	serverReader, clientWriter := io.Pipe()
+
+	srv := &Server{
+		Rules: rules.FormatRules(),
+		LoadConfig: func(string) (*config.Config, error) {
+			return config.DefaultConfig(), nil
+		},
+	}
+
+	c := &client{
+		t:        t,
+		conn:     newConn(clientReader, clientWriter),
+		incoming: make(chan *message, 16),
+		done:     make(chan error, 1),
+	}
+	go func() {
+		err := srv.Serve(serverReader, serverWriter)
+		serverWriter.Close()
+		c.done <- err
+	}()
+
+	// Read concurrently: the pipes are unbuffered, so the server blocks
+	// writing notifications until someone reads them.
+	go func() {
+		defer close(c.incoming)
+		for {
+			msg, err := c.conn.read()
+			if err != nil {
+				return
+			}
+			c.incoming <- msg
+		}
+	}()
+	t.Cleanup(func() { clientWriter.Close() })
+
+	return c
+}
+
+// call sends a request and decodes the result into result.
+func (c *client) call(method string, params, result any) *responseError {
+	c.t.Helper()
+
+	c.nextID++
+	id := json.RawMessage(strconv.Itoa(c.nextID))
+	data, err := json.Marshal(params)
+	if err != nil {
+		c.t.Fatal(err)
+	}
+	if err := c.conn.write(&message{ID: &id, Method: method, Params: data}); err != nil {
+		c.t.Fatal(err)
+	}
+
+	for {
+		msg, ok := <-c.incoming
+		if !ok {
+			c.t.Fatalf("%s: connection closed before response", method)
+		}
+		if msg.ID == nil {
+			c.notifications = append(c.notifications, msg)
+			continue
+		}
+		if string(*msg.ID) != string(id) {
+			c.t.Fatalf("%s: response id %s, want %s", method, *msg.ID, id)
+		}
+		if msg.Error != nil {
+			return msg.Error
+		}
+		if result != nil {
+			if err := json.Unmarshal(msg.Result, result); err != nil {
+				c.t.Fatalf("%s: decoding result: %v", method, err)
+			}
+		}
+		return nil
+	}
+}
+
+// notify sends a notification.
+func (c *client) notify(method string, params any) {
+	c.t.Helper()
+	if err := c.conn.notify(method, params); err != nil {
+		c.t.Fatal(err)
+	}
+}
+
+// nextNotification reads the next server notification with the given
+// method.
+func (c *client) nextNotification(method string) *message {
+	c.t.Helper()
+	for i, msg := range c.notifications {
+		if msg.Method == method {
+			c.notifications = append(c.notifications[:i], c.notifications[i+1:]...)
+			return msg
+		}
+	}
+	for {
+		msg, ok := <-c.incoming
+		if !ok {
+			c.t.Fatalf("connection closed waiting for %s", method)
+		}
+		if msg.Method == method {
+			return msg
+		}
+	}
+}
+
+func (c *client) initialize() {
+	c.t.Helper()
+	var result InitializeResult
+	if err := c.call("initialize", map[string]any{"capabilities": map[string]any{}}, &result); err != nil {
+		c.t.Fatalf("initialize: %v", err)
+	}
+	c.notify("initialized", struct{}{})
+}
+
+func (c *client) open(text string) {
+	c.t.Helper()
+	c.notify("textDocument/didOpen", &DidOpenTextDocumentParams{
+		TextDocument: TextDocumentItem{URI: testURI, LanguageID: "makefile", Version: 1, Text: text},
+	})
+}
+
+func TestInitialize(t *testing.T) {
+	c := newClient(t)
+
+	var result InitializeResult
+	if err := c.call("initialize", map[string]any{}, &result); err != nil {
+		t.Fatal(err)
+	}
+
+	caps := result.Capabilities
+	if caps.TextDocumentSync != textDocumentSyncFull {
+		t.Errorf("TextDocumentSync: got %d, want %d", caps.TextDocumentSync, textDocumentSyncFull)
+	}
+	if !caps.DocumentFormattingProvider || !caps.DocumentRangeFormattingProvider || !caps.DocumentSymbolProvider {
+		t.Errorf("missing capabilities: %+v", caps)
+	}
+	if caps.DocumentOnTypeFormattingProvider == nil || caps.DocumentOnTypeFormattingProvider.FirstTriggerCharacter != "\n" {
+		t.Errorf("onTypeFormatting: got %+v", caps.DocumentOnTypeFormattingProvider)
+	}
+	if result.ServerInfo.Name != "makefmt" {
+		t.Errorf("ServerInfo.Name: got %q", result.ServerInfo.Name)
+	}
+}
+
+func TestRequestBeforeInitialize(t *testing.T) {
+	c := newClient(t)
+
+	err := c.call("textDocument/formatting", &DocumentFormattingParams{}, nil)
+	if err == nil || err.Code != codeServerNotInitialized {
+		t.Errorf("got %v, want code %d", err, codeServerNotInitialized)
+	}
+}
+
+func TestUnknownMethod(t *testing.T) {
+	c := newClient(t)
+	c.initialize()
+
+	err := c.call("textDocument/hover", map[string]any{}, nil)
+	if err == nil || err.Code != codeMethodNotFound {
+		t.Errorf("got %v, want code %d", err, codeMethodNotFound)
+	}
+}
+
+func TestDiagnostics(t *testing.T) {
+	c := newClient(t)
+	c.initialize()
+	c.open("VAR:=val\n")
+
+	var params PublishDiagnosticsParams
+	msg := c.nextNotification("textDocument/publishDiagnostics")
+	if err := json.Unmarshal(msg.Params, &params); err != nil {
+		t.Fatal(err)
+	}
+
+	if params.URI != testURI {
+		t.Errorf("URI: got %q, want %q", params.URI, testURI)
+	}
+	if len(params.Diagnostics) != 1 {
+		t.Fatalf("got %d diagnostics, want 1: %+v", len(params.Diagnostics), params.Diagnostics)
+	}
+	d := params.Diagnostics[0]
+	wantRange := Range{Start: Position{Line: 0, Character: 3}, End: Position{Line: 0, Character: 5}}
+	if d.Range != wantRange {
+		t.Errorf("Range: got %+v, want %+v", d.Range, wantRange)
+	}
+	if d.Source != "makefmt" || d.Code != diagnosticCode {
+		t.Errorf("Source/Code: got %q/%q", d.Source, d.Code)
+	}
+
+	// Fixing the document clears the diagnostics.
+	c.notify("textDocument/didChange", &DidChangeTextDocumentParams{
+		TextDocument:   VersionedTextDocumentIdentifier{URI: testURI, Version: 2},
+		ContentChanges: []TextDocumentContentChangeEvent{{Text: "VAR := val\n"}},
+	})
+	msg = c.nextNotification("textDocument/publishDiagnostics")
+	if err := json.Unmarshal(msg.Params, &params); err != nil {
+		t.Fatal(err)
+	}
+	if len(params.Diagnostics) != 0 {
+		t.Errorf("after fix: got %+v, want no diagnostics", params.Diagnostics)
+	}
+}
+
+func TestDiagnosticsUnknownSuppressedRule(t *testing.T) {
+	c := newClient(t)
+	c.initialize()
+	c.open("# makefmt: disable=tab_widht\nVAR := val\n")
+
+	var params PublishDiagnosticsParams
+	msg := c.nextNotification("textDocument/publishDiagnostics")
+	if err := json.Unmarshal(msg.Params, &params); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(params.Diagnostics) != 1 {
+		t.Fatalf("got %d diagnostics, want 1: %+v", len(params.Diagnostics), params.Diagnostics)
+	}
+	d := params.Diagnostics[0]
+	wantRange := Range{Start: Position{Line: 0}, End: Position{Line: 1}}
+	if d.Range != wantRange || d.Code != suppressCode {
+		t.Errorf("got %+v, want range %+v and code %q", d, wantRange, suppressCode)
+	}
+	if want := `unknown rule "tab_widht" in suppression comment`; d.Message != want {
+		t.Errorf("Message: got %q, want %q", d.Message, want)
+	}
+}
+
+func TestFormatting(t *testing.T) {
+	c := newClient(t)
+	c.initialize()
+	src := "A:=1\n\n\n\n\nB:=2\n"
+	c.open(src)
+
+	var edits []TextEdit
+	if err := c.call("textDocument/formatting", &DocumentFormattingParams{
+		TextDocument: TextDocumentIdentifier{URI: testURI},
+	}, &edits); err != nil {
+		t.Fatal(err)
+	}
+
+	if got, want := applyTextEdits(t, src, edits), "A := 1\n\n\nB := 2\n"; got != want {
+		t.Errorf("formatted: got %q, want %q", got, want)
+	}
+}
+
+func TestRangeFormatting(t *testing.T) {
+	c := newClient(t)
+	c.initialize()
+	src := "A:=1\nB:=2\nC:=3\n"
+	c.open(src)
+
+	var edits []TextEdit
+	if err := c.call("textDocument/rangeFormatting", &DocumentRangeFormattingParams{
+		TextDocument: TextDocumentIdentifier{URI: testURI},
+		Range:        Range{Start: Position{Line: 1}, End: Position{Line: 2}},
+	}, &edits); err != nil {
+		t.Fatal(err)
+	}
+
+	if got, want := applyTextEdits(t, src, edits), "A:=1\nB := 2\nC:=3\n"; got != want {
+		t.Errorf("formatted: got %q, want %q", got, want)
+	}
+}
+
+func TestOnTypeFormatting(t *testing.T) {
+	c := newClient(t)
+	c.initialize()
+	src := "VAR:=1\n\nbuild:    main.go   \n"
+	c.open(src)
+
+	call := func(line int) []TextEdit {
+		t.Helper()
+		var edits []TextEdit
+		if err := c.call("textDocument/onTypeFormatting", &DocumentOnTypeFormattingParams{
+			TextDocument: TextDocumentIdentifier{URI: testURI},
+			Position:     Position{Line: line},
+			Ch:           "\n",
+		}, &edits); err != nil {
+			t.Fatal(err)
+		}
+		return edits
+	}
+
+	// Enter after the rule line formats only that line.
+	if got, want := applyTextEdits(t, src, call(3)), "VAR:=1\n\nbuild:    main.go\n"; got != want {
+		t.Errorf("after rule line: got %q, want %q", got, want)
+	}
+
+	// Enter after an assignment does nothing.
+	if edits := call(1); len(edits) != 0 {
+		t.Errorf("after assignment line: got %+v, want no edits", edits)
+	}
+}
+
+func TestDocumentSymbol(t *testing.T) {
+	c := newClient(t)
+	c.initialize()
+	c.open("GO := go\n\n##@ Build\n\nbuild: ## Build it\n\t$(GO) build\n\ntest:\n\t$(GO) test\n")
+
+	var symbols []DocumentSymbol
+	if err := c.call("textDocument/documentSymbol", &DocumentSymbolParams{
+		TextDocument: TextDocumentIdentifier{URI: testURI},
+	}, &symbols); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(symbols) != 2 {
+		t.Fatalf("got %d top-level symbols, want 2: %+v", len(symbols), symbols)
+	}
+	if symbols[0].Name != "GO" || symbols[0].Kind != SymbolKindVariable {
+		t.Errorf("symbol 0: got %q kind %d", symbols[0].Name, symbols[0].Kind)
+	}
+
+	section := symbols[1]
+	if section.Name != "Build" || section.Kind != SymbolKindNamespace {
+		t.Errorf("section: got %q kind %d", section.Name, section.Kind)
+	}
+	if len(section.Children) != 2 {
+		t.Fatalf("section children: got %d, want 2", len(section.Children))
+	}
+	build := section.Children[0]
+	if build.Name != "build" || build.Detail != "Build it" || build.Kind != SymbolKindFunction {
+		t.Errorf("build: got %+v", build)
+	}
+	if build.Range.Start.Line != 4 || build.Range.End.Line != 5 {
+		t.Errorf("build range: got %+v, want lines 4-5", build.Range)
+	}
+	if section.Range.End.Line != 8 {
+		t.Errorf("section range end: got line %d, want 8", section.Range.End.Line)
+	}
+}
+
+func TestCodeAction(t *testing.T) {
+	c := newClient(t)
+	c.initialize()
+	src := "A:=1\nB := 2\nC:=3\n"
+	c.open(src)
+
+	var actions []CodeAction
+	if err := c.call("textDocument/codeAction", &CodeActionParams{
+		TextDocument: TextDocumentIdentifier{URI: testURI},
+		Range:        Range{Start: Position{Line: 2}, End: Position{Line: 2, Character: 4}},
+	}, &actions); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(actions) != 2 {
+		t.Fatalf("got %d actions, want 2: %+v", len(actions), actions)
+	}
+
+	quickFix := actions[0]
+	if quickFix.Kind != CodeActionQuickFix {
+		t.Errorf("action 0 kind: got %q", quickFix.Kind)
+	}
+	if got, want := applyTextEdits(t, src, quickFix.Edit.Changes[testURI]), "A:=1\nB := 2\nC := 3\n"; got != want {
+		t.Errorf("quick fix: got %q, want %q", got, want)
+	}
+
+	fixAll := actions[1]
+	if fixAll.Kind != CodeActionSourceFixAll {
+		t.Errorf("action 1 kind: got %q", fixAll.Kind)
+	}
+	if got, want := applyTextEdits(t, src, fixAll.Edit.Changes[testURI]), "A := 1\nB := 2\nC := 3\n"; got != want {
+		t.Errorf("fix all: got %q, want %q", got, want)
+	}
+
+	// The "only" filter restricts the kinds returned.
+	if err := c.call("textDocument/codeAction", &CodeActionParams{
+		TextDocument: TextDocumentIdentifier{URI: testURI},
+		Context:      CodeActionContext{Only: []string{"source"}},
+	}, &actions); err != nil {
+		t.Fatal(err)
+	}
+	if len(actions) != 1 || actions[0].Kind != CodeActionSourceFixAll {
+		t.Errorf("only source: got %+v", actions)
+	}
+}
+
+func TestDocumentNotOpen(t *testing.T) {
+	c := newClient(t)
+	c.initialize()
+
+	err := c.call("textDocument/formatting", &DocumentFormattingParams{
+		TextDocument: TextDocumentIdentifier{URI: "file:///missing/Makefile"},
+	}, nil)
+	if err == nil || err.Code != codeInvalidParams {
+		t.Errorf("got %v, want code %d", err, codeInvalidParams)
+	}
+}
+
+func TestShutdownExit(t *testing.T) {
+	c := newClient(t)
+	c.initialize()
+
+	if err := c.call("shutdown", nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.call("textDocument/formatting", &DocumentFormattingParams{}, nil); err == nil || err.Code != codeInvalidRequest {
+		t.Errorf("request after shutdown: got %v, want code %d", err, codeInvalidRequest)
+	}
+
+	c.notify("exit", nil)
+	if err := <-c.done; err != nil {
+		t.Errorf("Serve: got %v, want nil", err)
+	}
+}
+
+func TestExitWithoutShutdown(t *testing.T) {
+	c := newClient(t)
+	c.initialize()
+
+	c.notify("exit", nil)
+	if err := <-c.done; !errors.Is(err, ErrExitWithoutShutdown) {
+		t.Errorf("Serve: got %v, want %v", err, ErrExitWithoutShutdown)
+	}
+}
+
+// applyTextEdits applies LSP edits to src by converting them back to
+// byte offsets.
+func applyTextEdits(t *testing.T, src string, edits []TextEdit) string {
+	t.Helper()
+	doc := newDocument(src)
+	converted := make([]diff.Edit, 0, len(edits))
+	for _, e := range edits {
+		converted = append(converted, diff.Edit{
+			Start:   doc.offset(e.Range.Start),
+			End:     doc.offset(e.Range.End),
+			NewText: e.NewText,
+		})
+	}
+	return diff.Apply(src, converted)
+}
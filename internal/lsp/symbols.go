@@ -0,0 +1,85 @@
+package lsp
+
+import (
+	"strings"
+
+	"github.com/donaldgifford/makefmt/internal/parser"
+)
+
+// documentSymbol lists ##@ sections, targets, and variables. Targets and
+// variables that follow a section header are nested under that section.
+func (s *Server) documentSymbol(p *DocumentSymbolParams) (any, error) {
+	text, err := s.text(p.TextDocument.URI)
+	if err != nil {
+		return nil, err
+	}
+	return buildSymbols(newDocument(text), parser.Parse(text)), nil
+}
+
+func buildSymbols(doc *document, nodes []*parser.Node) []DocumentSymbol {
+	symbols := []DocumentSymbol{}
+	var section *DocumentSymbol
+
+	for _, n := range nodes {
+		sym, ok := nodeSymbol(doc, n)
+		if !ok {
+			continue
+		}
+
+		if sym.Kind == SymbolKindNamespace {
+			symbols = append(symbols, sym)
+			section = &symbols[len(symbols)-1]
+			continue
+		}
+
+		if section == nil {
+			symbols = append(symbols, sym)
+			continue
+		}
+		section.Children = append(section.Children, sym)
+		section.Range.End = sym.Range.End
+	}
+
+	return symbols
+}
+
+// nodeSymbol returns the symbol for a node, if it defines one.
+func nodeSymbol(doc *document, n *parser.Node) (DocumentSymbol, bool) {
+	first := n.Line - 1
+	last := nodeEndLine(n) - 1
+	sym := DocumentSymbol{
+		Range:          Range{Start: Position{Line: first}, End: doc.lineRange(last).End},
+		SelectionRange: doc.lineRange(first),
+	}
+
+	switch n.Type {
+	case parser.NodeSectionHeader:
+		sym.Name = n.Fields.Text
+		sym.Kind = SymbolKindNamespace
+	case parser.NodeRule:
+		sym.Name = strings.Join(n.Fields.Targets, " ")
+		sym.Detail = n.Fields.InlineHelp
+		sym.Kind = SymbolKindFunction
+	case parser.NodeAssignment:
+		sym.Name = n.Fields.VarName
+		sym.Detail = n.Fields.AssignOp
+		sym.Kind = SymbolKindVariable
+	default:
+		return DocumentSymbol{}, false
+	}
+
+	if sym.Name == "" {
+		return DocumentSymbol{}, false
+	}
+	return sym, true
+}
+
+// nodeEndLine returns the 1-indexed last line covered by n, including
+// continuation lines and children.
+func nodeEndLine(n *parser.Node) int {
+	end := n.Line + strings.Count(n.Raw, "\n")
+	for _, child := range n.Children {
+		end = max(end, nodeEndLine(child))
+	}
+	return end
+}
@@ -17,16 +17,19 @@ const (
 	NodeAssignment
 	// NodeRule is a target definition (target: prerequisites).
 	NodeRule
-	// NodeRecipe is a recipe line (\t command).
+	// NodeRecipe is a recipe line (\t command), including shell comments.
 	NodeRecipe
 	// NodeConditional is a conditional directive (ifeq/ifdef/ifndef/else/endif).
 	NodeConditional
 	// NodeInclude is an include directive (include, -include, sinclude).
 	NodeInclude
-	// NodeDirective is a special directive (.PHONY, .DEFAULT_GOAL, export, etc.).
+	// NodeDirective is a directive or special target (vpath, .PHONY, etc.).
+	// See the directive catalogue in directives.go.
 	NodeDirective
 	// NodeRaw is an unparseable line preserved verbatim (incl. define/endef).
 	NodeRaw
+	// NodeExport is an export or unexport line without an assignment.
+	NodeExport
 )
 
 //go:generate stringer -type=NodeType
@@ -43,18 +46,32 @@ type Node struct {
 // NodeFields holds type-specific parsed data for a Node.
 type NodeFields struct {
 	// Assignment fields.
-	VarName  string
-	AssignOp string // =, :=, ::=, ?=, +=, !=
-	VarValue string
+	Modifiers []string // export, override, private — in source order.
+	VarName   string
+	AssignOp  string // =, :=, ::=, ?=, +=, !=
+	VarValue  string
+
+	// Trailing comment on an assignment, rule, conditional, include,
+	// export or directive line ("VAR := value  # note").
+	InlineComment string // From the "#" to the end of the line.
+	CommentSpace  string // Whitespace between the line's content and InlineComment.
+
+	// Export fields (Directive holds export or unexport).
+	Names []string // Variables named; empty exports or unexports all.
 
 	// Rule fields.
 	Targets       []string
+	DoubleColon   bool // "targets:: prerequisites" rather than a single colon.
 	Prerequisites []string
 	OrderOnly     []string // After |
 	InlineHelp    string   // "## Description" trailing comment on rule lines.
+	InlineRecipe  string   // Recipe text after ";" on a rule line.
+
+	// Recipe fields.
+	ShellComment bool // "\t# text": a comment passed to the shell, not a Make comment.
 
 	// Conditional fields.
-	Directive string // ifeq, ifneq, ifdef, ifndef, else, endif.
+	Directive string // ifeq, ifneq, ifdef, ifndef, else, endif; export, unexport; directive name.
 	Condition string // The condition expression.
 
 	// Include fields.
@@ -65,6 +82,11 @@ type NodeFields struct {
 	Text   string
 	Inline bool   // Trailing comment on another line.
 	Prefix string // "#", "##", "##@" — preserved exactly by the writer.
+
+	// Suppression comment fields ("# makefmt: ..." on a NodeComment or
+	// a shell comment recipe line).
+	Suppress      SuppressKind
+	SuppressRules []string // Rule names for disable= and enable=.
 }
 
 // Clone returns a deep copy of the node.
@@ -94,10 +116,13 @@ func (n *Node) Clone() *Node {
 func (f *NodeFields) clone() NodeFields {
 	c := *f
 
+	c.Modifiers = cloneStrings(f.Modifiers)
+	c.Names = cloneStrings(f.Names)
 	c.Targets = cloneStrings(f.Targets)
 	c.Prerequisites = cloneStrings(f.Prerequisites)
 	c.OrderOnly = cloneStrings(f.OrderOnly)
 	c.Paths = cloneStrings(f.Paths)
+	c.SuppressRules = cloneStrings(f.SuppressRules)
 
 	return c
 }
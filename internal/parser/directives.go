@@ -0,0 +1,126 @@
+package parser
+
+import "sort"
+
+// DirectiveKind says how a directive, special target or special variable
+// is parsed.
+type DirectiveKind int
+
+const (
+	// DirectiveStandalone is a keyword with its own line syntax
+	// (vpath %.c src, undefine VAR, include foo.mk).
+	DirectiveStandalone DirectiveKind = iota
+	// DirectiveTargetList is a special target followed by a colon and a
+	// list of names (.PHONY: build test). It is parsed as a NodeDirective
+	// whose Targets and Prerequisites are filled in.
+	DirectiveTargetList
+	// DirectiveRule is a special target that takes a recipe (.DEFAULT:).
+	// It is parsed as a NodeRule.
+	DirectiveRule
+	// DirectiveAssignment is a special variable (.DEFAULT_GOAL := help).
+	// It is parsed as a NodeAssignment.
+	DirectiveAssignment
+	// DirectivePrerequisite is a special name that only appears in
+	// prerequisite lists (all: a .WAIT b).
+	DirectivePrerequisite
+)
+
+// DirectiveInfo is one entry of the GNU Make directive catalogue.
+type DirectiveInfo struct {
+	Name        string
+	Kind        DirectiveKind
+	Description string
+}
+
+// directives catalogues the directives, special targets and special
+// variables of GNU Make 4.4.
+var directives = []DirectiveInfo{
+	// Directives.
+	{"define", DirectiveStandalone, "Starts a multi-line variable definition."},
+	{"endef", DirectiveStandalone, "Ends a multi-line variable definition."},
+	{"undefine", DirectiveStandalone, "Removes a variable definition."},
+	{"ifeq", DirectiveStandalone, "Conditional: true when two values are equal."},
+	{"ifneq", DirectiveStandalone, "Conditional: true when two values differ."},
+	{"ifdef", DirectiveStandalone, "Conditional: true when a variable is non-empty."},
+	{"ifndef", DirectiveStandalone, "Conditional: true when a variable is empty."},
+	{"else", DirectiveStandalone, "Starts the alternative branch of a conditional."},
+	{"endif", DirectiveStandalone, "Ends a conditional."},
+	{"include", DirectiveStandalone, "Reads other makefiles; missing files are an error."},
+	{"-include", DirectiveStandalone, "Reads other makefiles, ignoring missing ones."},
+	{"sinclude", DirectiveStandalone, "Same as -include, for compatibility."},
+	{"override", DirectiveStandalone, "Sets a variable even if it was set on the command line."},
+	{"export", DirectiveStandalone, "Passes variables to sub-processes."},
+	{"unexport", DirectiveStandalone, "Stops passing variables to sub-processes."},
+	{"private", DirectiveStandalone, "Keeps a variable from being inherited by prerequisites."},
+	{"vpath", DirectiveStandalone, "Sets a search path for files matching a pattern."},
+	{"load", DirectiveStandalone, "Loads a dynamic object extension."},
+	{"-load", DirectiveStandalone, "Loads a dynamic object extension, ignoring failures."},
+
+	// Special targets.
+	{".PHONY", DirectiveTargetList, "Targets that are not files."},
+	{".SUFFIXES", DirectiveTargetList, "Suffixes used by suffix rules."},
+	{".DEFAULT", DirectiveRule, "Recipe for targets with no rule."},
+	{".PRECIOUS", DirectiveTargetList, "Targets not deleted when make is interrupted."},
+	{".INTERMEDIATE", DirectiveTargetList, "Targets treated as intermediate files."},
+	{".NOTINTERMEDIATE", DirectiveTargetList, "Targets never treated as intermediate files."},
+	{".SECONDARY", DirectiveTargetList, "Intermediate targets that are never deleted."},
+	{".SECONDEXPANSION", DirectiveTargetList, "Expands prerequisites a second time."},
+	{".DELETE_ON_ERROR", DirectiveTargetList, "Deletes a target whose recipe fails."},
+	{".IGNORE", DirectiveTargetList, "Ignores recipe errors for targets."},
+	{".LOW_RESOLUTION_TIME", DirectiveTargetList, "Targets with low-resolution timestamps."},
+	{".SILENT", DirectiveTargetList, "Does not echo recipes for targets."},
+	{".EXPORT_ALL_VARIABLES", DirectiveTargetList, "Exports all variables by default."},
+	{".NOTPARALLEL", DirectiveTargetList, "Runs targets serially."},
+	{".ONESHELL", DirectiveTargetList, "Runs each recipe in a single shell."},
+	{".POSIX", DirectiveTargetList, "Runs in POSIX-conforming mode."},
+	{".WAIT", DirectivePrerequisite, "Waits for earlier prerequisites before later ones."},
+
+	// Special variables.
+	{".DEFAULT_GOAL", DirectiveAssignment, "Goal built when none is given."},
+	{".RECIPEPREFIX", DirectiveAssignment, "Character that starts recipe lines."},
+	{".SHELLFLAGS", DirectiveAssignment, "Arguments passed to the shell."},
+	{".EXTRA_PREREQS", DirectiveAssignment, "Prerequisites added to every target."},
+	{".VARIABLES", DirectiveAssignment, "Names of all global variables (read-only)."},
+	{".FEATURES", DirectiveAssignment, "Features supported by this make (read-only)."},
+	{".INCLUDE_DIRS", DirectiveAssignment, "Directories searched for included makefiles (read-only)."},
+	{".LIBPATTERNS", DirectiveAssignment, "Patterns used to find -lNAME libraries."},
+	{".LOADED", DirectiveAssignment, "Dynamic objects loaded with load (read-only)."},
+	{"MAKEFILE_LIST", DirectiveAssignment, "Makefiles read so far."},
+	{"MAKEFILES", DirectiveAssignment, "Makefiles read before the others."},
+	{"MAKEFLAGS", DirectiveAssignment, "Flags passed to sub-makes."},
+	{"MAKECMDGOALS", DirectiveAssignment, "Goals given on the command line."},
+	{"MAKELEVEL", DirectiveAssignment, "Recursion depth of sub-makes."},
+	{"MAKE_RESTARTS", DirectiveAssignment, "Number of times make has restarted."},
+	{"MAKE_TERMOUT", DirectiveAssignment, "Set when standard output is a terminal."},
+	{"MAKE_TERMERR", DirectiveAssignment, "Set when standard error is a terminal."},
+	{"MAKESHELL", DirectiveAssignment, "Shell used on MS-DOS."},
+	{"CURDIR", DirectiveAssignment, "Current working directory."},
+	{"SHELL", DirectiveAssignment, "Shell used to run recipes."},
+	{"VPATH", DirectiveAssignment, "Search path for all prerequisites."},
+	{"GPATH", DirectiveAssignment, "Directories where targets are rebuilt in place."},
+	{"SUFFIXES", DirectiveAssignment, "Default suffix list (read-only)."},
+}
+
+// directiveIndex maps names to their catalogue entries.
+var directiveIndex = func() map[string]DirectiveInfo {
+	index := make(map[string]DirectiveInfo, len(directives))
+	for _, d := range directives {
+		index[d.Name] = d
+	}
+	return index
+}()
+
+// LookupDirective returns the catalogue entry for a directive, special
+// target or special variable name.
+func LookupDirective(name string) (DirectiveInfo, bool) {
+	d, ok := directiveIndex[name]
+	return d, ok
+}
+
+// Directives returns the whole catalogue, sorted by name.
+func Directives() []DirectiveInfo {
+	out := make([]DirectiveInfo, len(directives))
+	copy(out, directives)
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
@@ -0,0 +1,43 @@
+package parser
+
+import (
+	"sort"
+	"testing"
+)
+
+func TestDirectiveCatalogue(t *testing.T) {
+	seen := map[string]bool{}
+	for _, d := range directives {
+		if seen[d.Name] {
+			t.Errorf("duplicate catalogue entry %q", d.Name)
+		}
+		seen[d.Name] = true
+		if d.Description == "" {
+			t.Errorf("%q has no description", d.Name)
+		}
+	}
+
+	// Keywords the parser handles on its own must be catalogued too.
+	for _, keywords := range []map[string]bool{conditionalKeywords, includeKeywords, exportKeywords, assignModifiers} {
+		for keyword := range keywords {
+			d, ok := LookupDirective(keyword)
+			if !ok {
+				t.Errorf("keyword %q is missing from the catalogue", keyword)
+				continue
+			}
+			if d.Kind != DirectiveStandalone {
+				t.Errorf("keyword %q: want DirectiveStandalone, got %v", keyword, d.Kind)
+			}
+		}
+	}
+}
+
+func TestDirectivesSorted(t *testing.T) {
+	all := Directives()
+	if len(all) != len(directives) {
+		t.Fatalf("Directives() returned %d entries, want %d", len(all), len(directives))
+	}
+	if !sort.SliceIsSorted(all, func(i, j int) bool { return all[i].Name < all[j].Name }) {
+		t.Error("Directives() is not sorted by name")
+	}
+}
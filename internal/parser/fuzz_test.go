@@ -20,6 +20,8 @@ func FuzzParse(f *testing.F) {
 		"\n",
 		"",
 		"log-%:\n\t@grep -h '^$$*' $(MAKEFILE_LIST)\n",
+		"export override CFLAGS += -g\nunexport SECRET\n",
+		".SECONDEXPANSION:\n.DEFAULT:\n\t@echo $@\nvpath %.c src\nundefine FOO\n",
 	}
 
 	for _, s := range seeds {
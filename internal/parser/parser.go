@@ -26,25 +26,18 @@ var includeKeywords = map[string]bool{
 	"sinclude": true,
 }
 
-// Directive keywords that start a line (non-conditional, non-include).
-var directiveKeywords = map[string]bool{
-	".PHONY":                true,
-	".DEFAULT_GOAL":         true,
-	".SUFFIXES":             true,
-	".DELETE_ON_ERROR":      true,
-	".SECONDARY":            true,
-	".PRECIOUS":             true,
-	".INTERMEDIATE":         true,
-	".NOTPARALLEL":          true,
-	".ONESHELL":             true,
-	".POSIX":                true,
-	".SILENT":               true,
-	".IGNORE":               true,
-	".EXPORT_ALL_VARIABLES": true,
-	"export":                true,
-	"unexport":              true,
-	"vpath":                 true,
-	"override":              true,
+// Assignment modifier keywords, which may precede an assignment in any
+// combination (e.g., "export override VAR := value").
+var assignModifiers = map[string]bool{
+	"export":   true,
+	"override": true,
+	"private":  true,
+}
+
+// Export directive keywords, which name variables without assigning them.
+var exportKeywords = map[string]bool{
+	"export":   true,
+	"unexport": true,
 }
 
 // bannerRe matches decorative comment lines:
@@ -107,13 +100,15 @@ func (p *state) addNode(node *Node) {
 		p.nodes = append(p.nodes, node)
 
 	case NodeRecipe:
-		// Attach as child of the most recent rule node.
-		if len(p.nodes) > 0 {
-			parent := p.findRuleParent()
-			if parent != nil {
-				parent.Children = append(parent.Children, node)
-				return
-			}
+		// Attach as child of the most recent rule node. Make comments
+		// between the rule and this line move with it so that they keep
+		// their place among the recipe lines.
+		if i := p.findRuleParent(); i >= 0 {
+			parent := p.nodes[i]
+			parent.Children = append(parent.Children, p.nodes[i+1:]...)
+			parent.Children = append(parent.Children, node)
+			p.nodes = p.nodes[:i+1]
+			return
 		}
 		// No parent rule found; treat as raw.
 		node.Type = NodeRaw
@@ -135,21 +130,22 @@ func (p *state) addNode(node *Node) {
 	}
 }
 
-// findRuleParent returns the most recent NodeRule in the top-level nodes.
-func (p *state) findRuleParent() *Node {
+// findRuleParent returns the index of the most recent NodeRule in the
+// top-level nodes, or -1 if a line other than a comment ends the search.
+func (p *state) findRuleParent() int {
 	for i := len(p.nodes) - 1; i >= 0; i-- {
 		if p.nodes[i].Type == NodeRule {
-			return p.nodes[i]
+			return i
 		}
-		// Stop searching at non-recipe, non-comment, non-blank nodes.
+		// Stop searching at non-comment nodes.
 		switch p.nodes[i].Type {
-		case NodeRecipe, NodeComment, NodeBannerComment, NodeSectionHeader, NodeBlankLine:
+		case NodeComment, NodeBannerComment, NodeSectionHeader:
 			continue
 		default:
-			return nil
+			return -1
 		}
 	}
-	return nil
+	return -1
 }
 
 // handleDefineBlock consumes lines until endef.
@@ -185,13 +181,19 @@ func (p *state) classifyLine(joined, raw string) *Node {
 		return &Node{Type: NodeBlankLine, Raw: raw}
 	}
 
-	// 2. Check for define blocks.
-	if strings.HasPrefix(trimmed, "define ") || trimmed == "define" {
+	// 2. Recipe: starts with tab and we're in a rule context. This comes
+	// before comments because make passes "\t# text" to the shell.
+	if strings.HasPrefix(joined, "\t") && p.inRule {
+		return parseRecipe(joined, raw)
+	}
+
+	// 3. Check for define blocks, which may carry modifiers.
+	if _, rest := splitModifiers(trimmed); strings.HasPrefix(rest, "define ") || rest == "define" {
 		p.inDefine = true
 		return &Node{Type: NodeRaw, Raw: raw}
 	}
 
-	// 3. Section header: ##@ ...
+	// 4. Section header: ##@ ...
 	if strings.HasPrefix(trimmed, "##@") {
 		text := strings.TrimSpace(trimmed[3:])
 		return &Node{
@@ -204,7 +206,7 @@ func (p *state) classifyLine(joined, raw string) *Node {
 		}
 	}
 
-	// 4. Banner comment: decorative separator.
+	// 5. Banner comment: decorative separator.
 	if isBannerComment(trimmed) {
 		return &Node{
 			Type: NodeBannerComment,
@@ -215,49 +217,65 @@ func (p *state) classifyLine(joined, raw string) *Node {
 		}
 	}
 
-	// 5. Comment: starts with #.
+	// 6. Comment: starts with #.
 	if strings.HasPrefix(trimmed, "#") {
 		return parseComment(trimmed, raw)
 	}
 
-	// 6. Recipe: starts with tab and we're in a rule context.
-	if strings.HasPrefix(joined, "\t") && p.inRule {
-		return &Node{
-			Type: NodeRecipe,
-			Raw:  raw,
-			Fields: NodeFields{
-				Text: strings.TrimPrefix(joined, "\t"),
-			},
-		}
+	// 7. Everything else may end in a trailing comment. Rules find their
+	// own, since "#" after ";" is part of an inline recipe.
+	code, space, comment := splitComment(trimmed)
+	node := classifyStatement(code, trimmed, raw)
+	if node.Type != NodeRule && node.Type != NodeRaw {
+		node.Fields.InlineComment = comment
+		node.Fields.CommentSpace = space
 	}
+	return node
+}
 
-	// 7. Conditional: ifeq, ifdef, ifndef, else, endif.
-	if node := tryConditional(trimmed, raw); node != nil {
+// classifyStatement classifies a line that is not blank, a comment or a
+// recipe. code is the line without its trailing comment.
+func classifyStatement(code, trimmed, raw string) *Node {
+	// 1. Conditional: ifeq, ifdef, ifndef, else, endif.
+	if node := tryConditional(code, raw); node != nil {
 		return node
 	}
 
-	// 8. Include: include, -include, sinclude.
-	if node := tryInclude(trimmed, raw); node != nil {
+	// 2. Include: include, -include, sinclude.
+	if node := tryInclude(code, raw); node != nil {
 		return node
 	}
 
-	// 9. Directive: .PHONY, export, etc. (before assignment/rule to prevent
-	// ".PHONY: x" being parsed as a rule or ".DEFAULT_GOAL := x" as assignment).
-	if node := tryDirective(trimmed, raw); node != nil {
+	// 3. Assignment with modifiers: export, override, private.
+	if mods, rest := splitModifiers(code); len(mods) > 0 {
+		if node := tryAssignment(rest, raw); node != nil {
+			node.Fields.Modifiers = mods
+			return node
+		}
+	}
+
+	// 4. Export: export or unexport without an assignment.
+	if node := tryExport(code, raw); node != nil {
 		return node
 	}
 
-	// 10. Assignment: contains assignment operator.
-	if node := tryAssignment(trimmed, raw); node != nil {
+	// 5. Directive: vpath, undefine, special targets such as .PHONY, etc.
+	// (before assignment/rule so ".PHONY: x" is not parsed as a rule).
+	if node := tryDirective(code, raw); node != nil {
 		return node
 	}
 
-	// 11. Rule: contains : with target pattern.
+	// 6. Assignment: contains assignment operator.
+	if node := tryAssignment(code, raw); node != nil {
+		return node
+	}
+
+	// 7. Rule: contains : with target pattern.
 	if node := tryRule(trimmed, raw); node != nil {
 		return node
 	}
 
-	// 12. Raw: anything else.
+	// 8. Raw: anything else.
 	return &Node{Type: NodeRaw, Raw: raw}
 }
 
@@ -272,6 +290,26 @@ func isBannerComment(trimmed string) bool {
 	return bannerRe.MatchString(trimmed)
 }
 
+// parseRecipe builds a recipe node. A recipe line whose command starts
+// with # is a shell comment: make passes it to the shell unchanged.
+func parseRecipe(joined, raw string) *Node {
+	text := strings.TrimPrefix(joined, "\t")
+	n := &Node{
+		Type: NodeRecipe,
+		Raw:  raw,
+		Fields: NodeFields{
+			Text: text,
+		},
+	}
+
+	if comment := strings.TrimSpace(text); strings.HasPrefix(comment, "#") {
+		n.Fields.ShellComment = true
+		n.Fields.Suppress, n.Fields.SuppressRules = parseSuppression(strings.TrimSpace(strings.TrimLeft(comment, "#")))
+	}
+
+	return n
+}
+
 func parseComment(trimmed, raw string) *Node {
 	// Determine prefix: ## or #.
 	prefix := "#"
@@ -281,13 +319,16 @@ func parseComment(trimmed, raw string) *Node {
 
 	text := strings.TrimPrefix(trimmed, prefix)
 	text = strings.TrimSpace(text)
+	suppress, rules := parseSuppression(text)
 
 	return &Node{
 		Type: NodeComment,
 		Raw:  raw,
 		Fields: NodeFields{
-			Text:   text,
-			Prefix: prefix,
+			Text:          text,
+			Prefix:        prefix,
+			Suppress:      suppress,
+			SuppressRules: rules,
 		},
 	}
 }
@@ -394,11 +435,7 @@ func extractVarName(before, op string) (string, bool) {
 	}
 
 	if strings.ContainsAny(varName, " \t") {
-		parts := strings.Fields(varName)
-		if len(parts) != 2 || parts[0] != "override" {
-			return "", false
-		}
-		varName = "override " + parts[1]
+		return "", false
 	}
 
 	if strings.Contains(varName, ":") && op == "=" {
@@ -407,6 +444,45 @@ func extractVarName(before, op string) (string, bool) {
 	return varName, true
 }
 
+// splitModifiers removes leading assignment modifiers from trimmed and
+// returns them with the remaining text. A modifier must be followed by
+// more text, so a bare "export" is not one.
+func splitModifiers(trimmed string) ([]string, string) {
+	var mods []string
+	rest := trimmed
+	for {
+		idx := strings.IndexAny(rest, " \t")
+		if idx < 0 || !assignModifiers[rest[:idx]] {
+			return mods, rest
+		}
+		mods = append(mods, rest[:idx])
+		rest = strings.TrimSpace(rest[idx:])
+	}
+}
+
+func tryExport(trimmed, raw string) *Node {
+	keyword := trimmed
+	if idx := strings.IndexAny(trimmed, " \t"); idx >= 0 {
+		keyword = trimmed[:idx]
+	}
+	if !exportKeywords[keyword] {
+		return nil
+	}
+
+	var names []string
+	if rest := strings.TrimSpace(trimmed[len(keyword):]); rest != "" {
+		names = strings.Fields(rest)
+	}
+	return &Node{
+		Type: NodeExport,
+		Raw:  raw,
+		Fields: NodeFields{
+			Directive: keyword,
+			Names:     names,
+		},
+	}
+}
+
 func tryRule(trimmed, raw string) *Node {
 	// Find the colon that separates targets from prerequisites.
 	// Must not be part of ::= or := assignment operators.
@@ -422,21 +498,33 @@ func tryRule(trimmed, raw string) *Node {
 
 	targets := strings.Fields(targetStr)
 
-	// Parse the rest: prerequisites and optional inline help.
-	rest := ""
-	if colonIdx+1 < len(trimmed) {
-		rest = strings.TrimSpace(trimmed[colonIdx+1:])
-	}
+	// Parse the rest: prerequisites and an optional inline recipe, help
+	// text or comment.
+	rest := trimmed[colonIdx+1:]
+	rest, doubleColon := strings.CutPrefix(rest, ":")
 
 	var prerequisites []string
 	var orderOnly []string
 	var inlineHelp string
-
-	// Check for inline help comment: ## at end of line.
-	if before, after, found := strings.Cut(rest, "##"); found {
-		inlineHelp = strings.TrimSpace(after)
-		rest = strings.TrimSpace(before)
+	var inlineRecipe string
+	var inlineComment, commentSpace string
+
+	if idx := inlineRecipeIndex(rest); idx >= 0 {
+		// Everything after the ";", including any "#", is recipe text.
+		inlineRecipe = strings.TrimSpace(rest[idx+1:])
+		rest = rest[:idx]
+	} else {
+		// A "##" comment is help text for the target.
+		var comment string
+		rest, commentSpace, comment = splitComment(rest)
+		if help, ok := strings.CutPrefix(comment, "##"); ok {
+			inlineHelp = strings.TrimSpace(help)
+			commentSpace = ""
+		} else {
+			inlineComment = comment
+		}
 	}
+	rest = strings.TrimSpace(rest)
 
 	// Split prerequisites at |.
 	if before, after, found := strings.Cut(rest, "|"); found {
@@ -457,16 +545,73 @@ func tryRule(trimmed, raw string) *Node {
 		Raw:  raw,
 		Fields: NodeFields{
 			Targets:       targets,
+			DoubleColon:   doubleColon,
 			Prerequisites: prerequisites,
 			OrderOnly:     orderOnly,
 			InlineHelp:    inlineHelp,
+			InlineRecipe:  inlineRecipe,
+			InlineComment: inlineComment,
+			CommentSpace:  commentSpace,
 		},
 	}
 }
 
+// splitComment splits a trailing comment off s. It returns the text
+// before the comment with trailing whitespace removed, that whitespace,
+// and the comment from its "#" with trailing whitespace removed.
+func splitComment(s string) (code, space, comment string) {
+	idx := indexUnnested(s, '#')
+	if idx < 0 {
+		return s, "", ""
+	}
+	code = strings.TrimRight(s[:idx], " \t")
+	return code, s[len(code):idx], strings.TrimRight(s[idx:], " \t")
+}
+
+// inlineRecipeIndex returns the index of the ";" that starts an inline
+// recipe on a rule line, or -1. A ";" inside a variable reference or
+// after a comment has started does not count.
+func inlineRecipeIndex(line string) int {
+	idx := indexUnnested(line, ';')
+	if hash := indexUnnested(line, '#'); hash >= 0 && hash < idx {
+		return -1
+	}
+	return idx
+}
+
+// indexUnnested returns the index of the first c in s that is neither
+// escaped with a backslash nor inside a $(...) or ${...} reference, or -1.
+// This is how make finds the "#" that starts a comment.
+func indexUnnested(s string, c byte) int {
+	depth := 0
+	for i := 0; i < len(s); i++ {
+		switch {
+		case s[i] == '\\':
+			i++ // Skip the escaped character.
+		case s[i] == '$' && i+1 < len(s) && (s[i+1] == '(' || s[i+1] == '{'):
+			depth++
+			i++
+		case depth > 0 && (s[i] == '(' || s[i] == '{'):
+			depth++
+		case depth > 0 && (s[i] == ')' || s[i] == '}'):
+			depth--
+		case depth == 0 && s[i] == c:
+			return i
+		}
+	}
+	return -1
+}
+
 // findRuleColon finds the index of the colon that separates targets from
 // prerequisites. Returns -1 if not found.
 func findRuleColon(line string) int {
+	// An inline recipe or comment may contain anything.
+	if idx := inlineRecipeIndex(line); idx >= 0 {
+		line = line[:idx]
+	} else {
+		line, _, _ = splitComment(line)
+	}
+
 	// Skip lines that look like assignments (contain =, :=, etc.).
 	for _, op := range assignOps {
 		if strings.Contains(line, op) {
@@ -483,21 +628,50 @@ func findRuleColon(line string) int {
 }
 
 func tryDirective(trimmed, raw string) *Node {
-	firstWord := trimmed
+	name := trimmed
 	if idx := strings.IndexAny(trimmed, " \t:"); idx >= 0 {
-		firstWord = trimmed[:idx]
+		name = trimmed[:idx]
 	}
+	rest := strings.TrimSpace(trimmed[len(name):])
 
-	if directiveKeywords[firstWord] {
-		return &Node{
-			Type: NodeDirective,
-			Raw:  raw,
-			Fields: NodeFields{
-				Text: trimmed,
-			},
+	info, ok := LookupDirective(name)
+	if !ok {
+		return nil
+	}
+
+	n := &Node{
+		Type: NodeDirective,
+		Raw:  raw,
+		Fields: NodeFields{
+			Directive: name,
+			Text:      trimmed,
+		},
+	}
+
+	switch info.Kind {
+	case DirectiveStandalone:
+		// "vpath: x" is a rule for a target named vpath.
+		if strings.HasPrefix(rest, ":") {
+			return nil
+		}
+		return n
+
+	case DirectiveTargetList:
+		names, ok := strings.CutPrefix(rest, ":")
+		if !ok || strings.HasPrefix(names, "=") || strings.HasPrefix(names, ":") {
+			return nil // An assignment to a variable with this name.
 		}
+		n.Fields.Targets = []string{name}
+		if names = strings.TrimSpace(names); names != "" {
+			n.Fields.Prerequisites = strings.Fields(names)
+		}
+		return n
+
+	default:
+		// Special rules and variables parse as ordinary rules and
+		// assignments.
+		return nil
 	}
-	return nil
 }
 
 // splitLines splits source into lines, preserving empty trailing lines.
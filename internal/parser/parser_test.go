@@ -1,6 +1,7 @@
 package parser
 
 import (
+	"strings"
 	"testing"
 )
 
@@ -162,6 +163,7 @@ func TestClassifyRule(t *testing.T) {
 		targets       []string
 		prerequisites []string
 		inlineHelp    string
+		doubleColon   bool
 	}{
 		{
 			name:    "simple rule",
@@ -197,6 +199,13 @@ func TestClassifyRule(t *testing.T) {
 			input:   "%:",
 			targets: []string{"%"},
 		},
+		{
+			name:          "double colon",
+			input:         "y:: a b",
+			targets:       []string{"y"},
+			prerequisites: []string{"a", "b"},
+			doubleColon:   true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -219,6 +228,138 @@ func TestClassifyRule(t *testing.T) {
 			if n.Fields.InlineHelp != tt.inlineHelp {
 				t.Errorf("InlineHelp: want %q, got %q", tt.inlineHelp, n.Fields.InlineHelp)
 			}
+			if n.Fields.DoubleColon != tt.doubleColon {
+				t.Errorf("DoubleColon: want %v, got %v", tt.doubleColon, n.Fields.DoubleColon)
+			}
+		})
+	}
+}
+
+func TestClassifyInlineRecipe(t *testing.T) {
+	tests := []struct {
+		name          string
+		input         string
+		prerequisites []string
+		inlineRecipe  string
+		inlineHelp    string
+	}{
+		{"no prerequisites", "clean: ; rm -rf build", nil, "rm -rf build", ""},
+		{"prerequisites", "all: a b ; @echo done", []string{"a", "b"}, "@echo done", ""},
+		{"no space", "all:a;echo a=b", []string{"a"}, "echo a=b", ""},
+		{"help comment in recipe", "all: a ; echo hi ## not help", []string{"a"}, "echo hi ## not help", ""},
+		{"semicolon in reference", "all: $(shell a;b)", []string{"$(shell", "a;b)"}, "", ""},
+		{"semicolon in help", "all: a ## Build; then test", []string{"a"}, "", "Build; then test"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			nodes := Parse(tt.input)
+			if len(nodes) != 1 {
+				t.Fatalf("expected 1 node, got %d", len(nodes))
+			}
+			n := nodes[0]
+			if n.Type != NodeRule {
+				t.Fatalf("expected NodeRule, got %v", n.Type)
+			}
+			if !slicesEqual(n.Fields.Prerequisites, tt.prerequisites) {
+				t.Errorf("Prerequisites: want %v, got %v", tt.prerequisites, n.Fields.Prerequisites)
+			}
+			if n.Fields.InlineRecipe != tt.inlineRecipe {
+				t.Errorf("InlineRecipe: want %q, got %q", tt.inlineRecipe, n.Fields.InlineRecipe)
+			}
+			if n.Fields.InlineHelp != tt.inlineHelp {
+				t.Errorf("InlineHelp: want %q, got %q", tt.inlineHelp, n.Fields.InlineHelp)
+			}
+		})
+	}
+}
+
+func TestClassifyInlineComment(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		typ     NodeType
+		comment string
+		space   string
+		check   func(NodeFields) string
+		value   string
+	}{
+		{
+			name: "assignment", input: "A := 1  # note", typ: NodeAssignment, comment: "# note", space: "  ",
+			check: func(f NodeFields) string { return f.VarValue },
+			value: "1",
+		},
+		{
+			name: "escaped hash", input: `A = a\#b # c`, typ: NodeAssignment, comment: "# c", space: " ",
+			check: func(f NodeFields) string { return f.VarValue },
+			value: `a\#b`,
+		},
+		{
+			name: "hash in reference", input: "A != $(shell echo '#') x", typ: NodeAssignment,
+			check: func(f NodeFields) string { return f.VarValue },
+			value: "$(shell echo '#') x",
+		},
+		{
+			name: "include", input: "include a.mk\t# generated", typ: NodeInclude, comment: "# generated", space: "\t",
+			check: func(f NodeFields) string { return strings.Join(f.Paths, " ") },
+			value: "a.mk",
+		},
+		{
+			name: "conditional", input: "ifdef DEBUG # debug builds", typ: NodeConditional, comment: "# debug builds", space: " ",
+			check: func(f NodeFields) string { return f.Condition },
+			value: "DEBUG",
+		},
+		{
+			name: "directive", input: ".PHONY: a b # phony", typ: NodeDirective, comment: "# phony", space: " ",
+			check: func(f NodeFields) string { return strings.Join(f.Prerequisites, " ") },
+			value: "a b",
+		},
+		{
+			name: "export", input: "export A # exported", typ: NodeExport, comment: "# exported", space: " ",
+			check: func(f NodeFields) string { return strings.Join(f.Names, " ") },
+			value: "A",
+		},
+		{
+			name: "rule", input: "build: a b # note", typ: NodeRule, comment: "# note", space: " ",
+			check: func(f NodeFields) string { return strings.Join(f.Prerequisites, " ") },
+			value: "a b",
+		},
+		{
+			name: "rule with = in comment", input: "release: # use TAG=v1.0.0", typ: NodeRule, comment: "# use TAG=v1.0.0", space: " ",
+			check: func(f NodeFields) string { return strings.Join(f.Prerequisites, " ") },
+			value: "",
+		},
+		{
+			name: "help is not a comment", input: "build: a ## Build it", typ: NodeRule,
+			check: func(f NodeFields) string { return f.InlineHelp },
+			value: "Build it",
+		},
+		{
+			name: "hash in inline recipe", input: "all: ; echo # shell", typ: NodeRule,
+			check: func(f NodeFields) string { return f.InlineRecipe },
+			value: "echo # shell",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			nodes := Parse(tt.input)
+			if len(nodes) != 1 {
+				t.Fatalf("expected 1 node, got %d", len(nodes))
+			}
+			n := nodes[0]
+			if n.Type != tt.typ {
+				t.Fatalf("expected %v, got %v", tt.typ, n.Type)
+			}
+			if n.Fields.InlineComment != tt.comment {
+				t.Errorf("InlineComment: want %q, got %q", tt.comment, n.Fields.InlineComment)
+			}
+			if n.Fields.CommentSpace != tt.space {
+				t.Errorf("CommentSpace: want %q, got %q", tt.space, n.Fields.CommentSpace)
+			}
+			if got := tt.check(n.Fields); got != tt.value {
+				t.Errorf("value: want %q, got %q", tt.value, got)
+			}
 		})
 	}
 }
@@ -247,6 +388,50 @@ func TestClassifyRecipe(t *testing.T) {
 	}
 }
 
+func TestClassifyRecipeComments(t *testing.T) {
+	input := "build:\n" +
+		"\t# install deps\n" +
+		"\tgo mod download\n" +
+		"# make comment\n" +
+		"\t##@ not a section\n" +
+		"\tgo build\n" +
+		"# trailing comment\n"
+	nodes := Parse(input)
+
+	if len(nodes) != 2 {
+		t.Fatalf("expected 2 top-level nodes, got %d", len(nodes))
+	}
+
+	want := []struct {
+		typ          NodeType
+		shellComment bool
+	}{
+		{NodeRecipe, true},
+		{NodeRecipe, false},
+		{NodeComment, false},
+		{NodeRecipe, true},
+		{NodeRecipe, false},
+	}
+
+	children := nodes[0].Children
+	if len(children) != len(want) {
+		t.Fatalf("expected %d children, got %d", len(want), len(children))
+	}
+	for i, w := range want {
+		if children[i].Type != w.typ || children[i].Fields.ShellComment != w.shellComment {
+			t.Errorf("child %d: want %v (shell comment %v), got %v (shell comment %v)",
+				i, w.typ, w.shellComment, children[i].Type, children[i].Fields.ShellComment)
+		}
+		if children[i].Line != i+2 {
+			t.Errorf("child %d: want line %d, got %d", i, i+2, children[i].Line)
+		}
+	}
+
+	if nodes[1].Type != NodeComment {
+		t.Errorf("trailing comment: expected NodeComment, got %v", nodes[1].Type)
+	}
+}
+
 func TestClassifyConditional(t *testing.T) {
 	tests := []struct {
 		name      string
@@ -316,14 +501,86 @@ func TestClassifyInclude(t *testing.T) {
 }
 
 func TestClassifyDirective(t *testing.T) {
+	tests := []struct {
+		name          string
+		input         string
+		directive     string
+		prerequisites []string
+	}{
+		{"phony", ".PHONY: build test", ".PHONY", []string{"build", "test"}},
+		{"vpath", "vpath %.c src", "vpath", nil},
+		{"override without assignment", "override undefine FOO", "override", nil},
+		{"undefine", "undefine FOO", "undefine", nil},
+		{"load", "load mk_funcs.so", "load", nil},
+		{"dash load", "-load mk_funcs.so", "-load", nil},
+		{"secondexpansion", ".SECONDEXPANSION:", ".SECONDEXPANSION", nil},
+		{"notintermediate", ".NOTINTERMEDIATE: foo.o", ".NOTINTERMEDIATE", []string{"foo.o"}},
+		{"low resolution time", ".LOW_RESOLUTION_TIME: dst", ".LOW_RESOLUTION_TIME", []string{"dst"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			nodes := Parse(tt.input)
+			if len(nodes) != 1 {
+				t.Fatalf("expected 1 node, got %d", len(nodes))
+			}
+			n := nodes[0]
+			if n.Type != NodeDirective {
+				t.Fatalf("expected NodeDirective, got %v for input %q", n.Type, tt.input)
+			}
+			if n.Fields.Directive != tt.directive {
+				t.Errorf("Directive: want %q, got %q", tt.directive, n.Fields.Directive)
+			}
+			if !slicesEqual(n.Fields.Prerequisites, tt.prerequisites) {
+				t.Errorf("Prerequisites: want %v, got %v", tt.prerequisites, n.Fields.Prerequisites)
+			}
+		})
+	}
+}
+
+func TestClassifySpecialNames(t *testing.T) {
 	tests := []struct {
 		name  string
 		input string
+		typ   NodeType
 	}{
-		{"phony", ".PHONY: build test"},
-		{"export", "export PATH"},
-		{"unexport", "unexport SECRET"},
-		{"default goal", ".DEFAULT_GOAL := help"},
+		{"default goal", ".DEFAULT_GOAL := help", NodeAssignment},
+		{"recipe prefix", ".RECIPEPREFIX = >", NodeAssignment},
+		{"shell flags", ".SHELLFLAGS := -ec", NodeAssignment},
+		{"extra prereqs", ".EXTRA_PREREQS += tools", NodeAssignment},
+		{"default rule", ".DEFAULT:\n\t@echo no rule for $@", NodeRule},
+		{"wait prerequisite", "all: a .WAIT b", NodeRule},
+		{"target named like a directive", "vpath: src", NodeRule},
+		{"variable named like a special target", ".PHONY := x", NodeAssignment},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			nodes := Parse(tt.input)
+			if len(nodes) != 1 {
+				t.Fatalf("expected 1 node, got %d", len(nodes))
+			}
+			if nodes[0].Type != tt.typ {
+				t.Errorf("expected %v, got %v for input %q", tt.typ, nodes[0].Type, tt.input)
+			}
+		})
+	}
+}
+
+func TestClassifyModifiedAssignment(t *testing.T) {
+	tests := []struct {
+		name      string
+		input     string
+		modifiers []string
+		varName   string
+		assignOp  string
+		varValue  string
+	}{
+		{"export", "export GOFLAGS := -mod=mod", []string{"export"}, "GOFLAGS", ":=", "-mod=mod"},
+		{"override", "override CFLAGS += -g", []string{"override"}, "CFLAGS", "+=", "-g"},
+		{"private", "private\tTMP = /tmp", []string{"private"}, "TMP", "=", "/tmp"},
+		{"combined", "export override  LDFLAGS ?= -s", []string{"export", "override"}, "LDFLAGS", "?=", "-s"},
+		{"no space", "export A:=1", []string{"export"}, "A", ":=", "1"},
 	}
 
 	for _, tt := range tests {
@@ -333,13 +590,71 @@ func TestClassifyDirective(t *testing.T) {
 				t.Fatalf("expected 1 node, got %d", len(nodes))
 			}
 			n := nodes[0]
-			if n.Type != NodeDirective {
-				t.Errorf("expected NodeDirective, got %v for input %q", n.Type, tt.input)
+			if n.Type != NodeAssignment {
+				t.Fatalf("expected NodeAssignment, got %v", n.Type)
+			}
+			if !slicesEqual(n.Fields.Modifiers, tt.modifiers) {
+				t.Errorf("Modifiers: want %v, got %v", tt.modifiers, n.Fields.Modifiers)
+			}
+			if n.Fields.VarName != tt.varName {
+				t.Errorf("VarName: want %q, got %q", tt.varName, n.Fields.VarName)
+			}
+			if n.Fields.AssignOp != tt.assignOp {
+				t.Errorf("AssignOp: want %q, got %q", tt.assignOp, n.Fields.AssignOp)
+			}
+			if n.Fields.VarValue != tt.varValue {
+				t.Errorf("VarValue: want %q, got %q", tt.varValue, n.Fields.VarValue)
+			}
+		})
+	}
+}
+
+func TestClassifyExport(t *testing.T) {
+	tests := []struct {
+		name      string
+		input     string
+		directive string
+		names     []string
+	}{
+		{"export one", "export PATH", "export", []string{"PATH"}},
+		{"export several", "export GOOS  GOARCH", "export", []string{"GOOS", "GOARCH"}},
+		{"export all", "export", "export", nil},
+		{"unexport", "unexport SECRET", "unexport", []string{"SECRET"}},
+		{"unexport all", "unexport", "unexport", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			nodes := Parse(tt.input)
+			if len(nodes) != 1 {
+				t.Fatalf("expected 1 node, got %d", len(nodes))
+			}
+			n := nodes[0]
+			if n.Type != NodeExport {
+				t.Fatalf("expected NodeExport, got %v", n.Type)
+			}
+			if n.Fields.Directive != tt.directive {
+				t.Errorf("Directive: want %q, got %q", tt.directive, n.Fields.Directive)
+			}
+			if !slicesEqual(n.Fields.Names, tt.names) {
+				t.Errorf("Names: want %v, got %v", tt.names, n.Fields.Names)
 			}
 		})
 	}
 }
 
+func TestModifiedDefineBlock(t *testing.T) {
+	input := "override define MY_FUNC\nA = 1\nendef\nB = 2"
+	nodes := Parse(input)
+
+	if len(nodes) != 2 {
+		t.Fatalf("expected 2 nodes, got %d", len(nodes))
+	}
+	if nodes[0].Type != NodeRaw || nodes[0].Raw != "override define MY_FUNC\nA = 1\nendef" {
+		t.Errorf("define block: got %v %q", nodes[0].Type, nodes[0].Raw)
+	}
+}
+
 func TestDefineBlock(t *testing.T) {
 	input := "define MY_FUNC\n\t@echo hello\n\t@echo world\nendef"
 	nodes := Parse(input)
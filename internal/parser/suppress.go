@@ -0,0 +1,193 @@
+package parser
+
+import (
+	"sort"
+	"strings"
+)
+
+// SuppressKind identifies a "# makefmt: ..." suppression comment.
+type SuppressKind int
+
+const (
+	// SuppressNone marks an ordinary comment.
+	SuppressNone SuppressKind = iota
+	// SuppressOff ("# makefmt: off") starts a region no rule may touch.
+	SuppressOff
+	// SuppressOn ("# makefmt: on") ends a region started by SuppressOff.
+	SuppressOn
+	// SuppressNextLine ("# makefmt: ignore-next-line") protects the next line.
+	SuppressNextLine
+	// SuppressDisable ("# makefmt: disable=a,b") turns the named rules off.
+	SuppressDisable
+	// SuppressEnable ("# makefmt: enable=a,b") turns disabled rules back on.
+	SuppressEnable
+)
+
+// suppressPrefix starts the text of every suppression comment.
+const suppressPrefix = "makefmt:"
+
+// parseSuppression recognizes the text of a suppression comment (after the
+// "#") and returns its kind and, for disable= and enable=, the rule names.
+func parseSuppression(text string) (SuppressKind, []string) {
+	rest, ok := strings.CutPrefix(text, suppressPrefix)
+	if !ok {
+		return SuppressNone, nil
+	}
+	rest = strings.TrimSpace(rest)
+
+	switch rest {
+	case "off":
+		return SuppressOff, nil
+	case "on":
+		return SuppressOn, nil
+	case "ignore-next-line":
+		return SuppressNextLine, nil
+	}
+
+	kinds := map[string]SuppressKind{
+		"disable=": SuppressDisable,
+		"enable=":  SuppressEnable,
+	}
+	for prefix, kind := range kinds {
+		list, ok := strings.CutPrefix(rest, prefix)
+		if !ok {
+			continue
+		}
+		var rules []string
+		for _, name := range strings.Split(list, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				rules = append(rules, name)
+			}
+		}
+		if len(rules) == 0 {
+			return SuppressNone, nil
+		}
+		return kind, rules
+	}
+
+	return SuppressNone, nil
+}
+
+// lineRange is an inclusive range of 1-indexed source lines.
+type lineRange struct {
+	start, end int
+}
+
+// Suppressions records the source lines covered by suppression comments.
+// Formatting rules leave covered nodes untouched, and lint rules should
+// skip diagnostics reported on covered lines.
+type Suppressions struct {
+	all   []lineRange            // Lines no rule may touch.
+	rules map[string][]lineRange // Lines each named rule may not touch.
+	names []RuleRef              // Rule names in disable= and enable=.
+}
+
+// RuleRef is a rule name given to a disable= or enable= suppression
+// comment, with the comment's line.
+type RuleRef struct {
+	Line int
+	Name string
+}
+
+// FindSuppressions scans nodes, including rule children, for suppression
+// comments and returns the lines they cover:
+//
+//   - off ... on covers both comments and every line between them, or up
+//     to the end of the file when on is missing.
+//   - ignore-next-line covers the node on the following line, including
+//     its continuation lines.
+//   - disable=rule covers the comment and every line after it up to a
+//     matching enable=rule, or up to the end of the file.
+func FindSuppressions(nodes []*Node) *Suppressions {
+	s := &Suppressions{rules: make(map[string][]lineRange)}
+
+	flat := flatten(nodes, nil)
+	sort.SliceStable(flat, func(i, j int) bool { return flat[i].Line < flat[j].Line })
+
+	offStart := 0
+	disabled := make(map[string]int)
+	nextLine := false
+	lastLine := 0
+
+	for _, n := range flat {
+		first, last := n.Line, n.Line+strings.Count(n.Raw, "\n")
+		lastLine = max(lastLine, last)
+
+		if nextLine {
+			s.all = append(s.all, lineRange{first, last})
+			nextLine = false
+		}
+
+		switch n.Fields.Suppress {
+		case SuppressOff:
+			if offStart == 0 {
+				offStart = first
+			}
+		case SuppressOn:
+			if offStart > 0 {
+				s.all = append(s.all, lineRange{offStart, last})
+				offStart = 0
+			}
+		case SuppressNextLine:
+			nextLine = true
+		case SuppressDisable:
+			for _, rule := range n.Fields.SuppressRules {
+				s.names = append(s.names, RuleRef{Line: first, Name: rule})
+				if _, ok := disabled[rule]; !ok {
+					disabled[rule] = first
+				}
+			}
+		case SuppressEnable:
+			for _, rule := range n.Fields.SuppressRules {
+				s.names = append(s.names, RuleRef{Line: first, Name: rule})
+				if start, ok := disabled[rule]; ok {
+					s.rules[rule] = append(s.rules[rule], lineRange{start, last})
+					delete(disabled, rule)
+				}
+			}
+		}
+	}
+
+	if offStart > 0 {
+		s.all = append(s.all, lineRange{offStart, lastLine})
+	}
+	for rule, start := range disabled {
+		s.rules[rule] = append(s.rules[rule], lineRange{start, lastLine})
+	}
+
+	return s
+}
+
+// Suppressed reports whether rule must leave the given line untouched.
+func (s *Suppressions) Suppressed(line int, rule string) bool {
+	return inRanges(s.all, line) || inRanges(s.rules[rule], line)
+}
+
+// Affects reports whether any line is suppressed for rule.
+func (s *Suppressions) Affects(rule string) bool {
+	return len(s.all) > 0 || len(s.rules[rule]) > 0
+}
+
+// RuleRefs returns the rule names given to disable= and enable= comments,
+// in line order, so callers can report names that match no rule.
+func (s *Suppressions) RuleRefs() []RuleRef {
+	return s.names
+}
+
+func inRanges(ranges []lineRange, line int) bool {
+	for _, r := range ranges {
+		if line >= r.start && line <= r.end {
+			return true
+		}
+	}
+	return false
+}
+
+// flatten appends nodes and all of their descendants to out.
+func flatten(nodes []*Node, out []*Node) []*Node {
+	for _, n := range nodes {
+		out = append(out, n)
+		out = flatten(n.Children, out)
+	}
+	return out
+}
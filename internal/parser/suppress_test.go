@@ -0,0 +1,101 @@
+package parser
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestParseSuppression(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		kind  SuppressKind
+		rules []string
+	}{
+		{"off", "# makefmt: off", SuppressOff, nil},
+		{"on without space", "#makefmt:on", SuppressOn, nil},
+		{"ignore next line", "# makefmt: ignore-next-line", SuppressNextLine, nil},
+		{"disable", "# makefmt: disable=align_backslashes, indent_conditionals", SuppressDisable, []string{"align_backslashes", "indent_conditionals"}},
+		{"enable", "# makefmt: enable=align_backslashes", SuppressEnable, []string{"align_backslashes"}},
+		{"disable without rules", "# makefmt: disable=", SuppressNone, nil},
+		{"unknown directive", "# makefmt: sometimes", SuppressNone, nil},
+		{"ordinary comment", "# turn makefmt: off", SuppressNone, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			nodes := Parse(tt.input)
+			if len(nodes) != 1 || nodes[0].Type != NodeComment {
+				t.Fatalf("expected 1 comment node, got %v", nodes)
+			}
+			f := nodes[0].Fields
+			if f.Suppress != tt.kind {
+				t.Errorf("kind: want %v, got %v", tt.kind, f.Suppress)
+			}
+			if !slices.Equal(f.SuppressRules, tt.rules) {
+				t.Errorf("rules: want %q, got %q", tt.rules, f.SuppressRules)
+			}
+		})
+	}
+}
+
+func TestFindSuppressions(t *testing.T) {
+	src := "A = 1\n" + // 1
+		"# makefmt: off\n" + // 2
+		"B = 2\n" + // 3
+		"# makefmt: on\n" + // 4
+		"C = 3\n" + // 5
+		"# makefmt: ignore-next-line\n" + // 6
+		"D = a \\\n" + // 7
+		"    b\n" + // 8
+		"E = 4\n" + // 9
+		"# makefmt: disable=x\n" + // 10
+		"F = 5\n" + // 11
+		"# makefmt: enable=x\n" + // 12
+		"all:\n" + // 13
+		"\t# makefmt: disable=y\n" + // 14
+		"\techo hi\n" // 15
+
+	s := FindSuppressions(Parse(src))
+
+	tests := []struct {
+		line int
+		rule string
+		want bool
+	}{
+		{1, "x", false},
+		{2, "x", true},
+		{3, "y", true},
+		{4, "x", true},
+		{5, "x", false},
+		{6, "x", false},
+		{7, "x", true},
+		{8, "x", true},
+		{9, "x", false},
+		{10, "x", true},
+		{11, "x", true},
+		{11, "y", false},
+		{12, "x", true},
+		{13, "x", false},
+		{15, "y", true},
+		{15, "x", false},
+	}
+
+	for _, tt := range tests {
+		if got := s.Suppressed(tt.line, tt.rule); got != tt.want {
+			t.Errorf("Suppressed(%d, %q) = %v, want %v", tt.line, tt.rule, got, tt.want)
+		}
+	}
+
+	wantRefs := []RuleRef{{10, "x"}, {12, "x"}, {14, "y"}}
+	if got := s.RuleRefs(); !slices.Equal(got, wantRefs) {
+		t.Errorf("RuleRefs() = %v, want %v", got, wantRefs)
+	}
+
+	if !s.Affects("z") {
+		t.Error("Affects(z) = false, want true while off/on regions exist")
+	}
+	if FindSuppressions(Parse("A = 1\n")).Affects("x") {
+		t.Error("Affects(x) = true for a file without suppression comments")
+	}
+}
@@ -1,7 +1,10 @@
 package format
 
 import (
+	"strings"
+
 	"github.com/donaldgifford/makefmt/internal/config"
+	"github.com/donaldgifford/makefmt/internal/formatter"
 	"github.com/donaldgifford/makefmt/internal/parser"
 )
 
@@ -13,9 +16,19 @@ func (*AssignmentSpacing) Name() string {
 	return "assignment_spacing"
 }
 
+// Description returns a one-line summary of the rule.
+func (*AssignmentSpacing) Description() string {
+	return "Normalizes spacing around assignment operators."
+}
+
+// Active reports whether assignment_spacing is anything but preserve.
+func (*AssignmentSpacing) Active(cfg *config.FormatterConfig) bool {
+	return cfg.AssignmentSpacing != "preserve"
+}
+
 // Format normalizes spacing around assignment operators based on config.
-func (*AssignmentSpacing) Format(nodes []*parser.Node, cfg *config.FormatterConfig) []*parser.Node {
-	if cfg.AssignmentSpacing == "preserve" {
+func (r *AssignmentSpacing) Format(nodes []*parser.Node, cfg *config.FormatterConfig) []*parser.Node {
+	if !r.Active(cfg) {
 		return nodes
 	}
 
@@ -31,24 +44,49 @@ func (*AssignmentSpacing) Format(nodes []*parser.Node, cfg *config.FormatterConf
 }
 
 func normalizeAssignment(n *parser.Node, mode string) *parser.Node {
+	if strings.Contains(n.Raw, "\n") {
+		return respaceFirstLine(n, mode)
+	}
+
 	clone := n.Clone()
 
-	// Clear Raw so the writer reconstructs from fields with proper spacing.
-	// The writer always emits "VarName <op> VarValue" with spaces.
+	// Clear Raw so the writer lays out the line from fields with proper
+	// spacing. The writer always emits "VarName <op> VarValue" with spaces.
 	switch mode {
 	case "space":
 		// Writer default is "VAR := val" (space around operator).
 		// Fields are already parsed without spacing, so clearing Raw suffices.
 		clone.Raw = ""
 	case "no_space":
-		// Reconstruct as "VAR:=val" — we need to set Raw directly since
-		// the writer's default includes spaces.
-		raw := clone.Fields.VarName + clone.Fields.AssignOp
-		if clone.Fields.VarValue != "" {
-			raw += clone.Fields.VarValue
+		// Lay out "VAR:=val" here, since the writer's default includes
+		// spaces.
+		docs := make([]formatter.Doc, 0, len(clone.Fields.Modifiers)+2)
+		for _, mod := range clone.Fields.Modifiers {
+			docs = append(docs, formatter.Text(mod+" "))
 		}
-		clone.Raw = raw
+		docs = append(docs,
+			formatter.Text(clone.Fields.VarName+clone.Fields.AssignOp+clone.Fields.VarValue),
+			formatter.CommentDoc(clone))
+		clone.Raw = formatter.Render(formatter.Concat(docs...), 0, 0)
 	}
 
 	return clone
 }
+
+// respaceFirstLine respaces the operator on the first line of a
+// continued assignment. The continuation lines are kept as written, since
+// laying the node out from its fields would join them into one line.
+func respaceFirstLine(n *parser.Node, mode string) *parser.Node {
+	_, _, value, ok := splitFirstLine(n)
+	if !ok {
+		return n
+	}
+
+	before, after := operatorSpacing(n, mode)
+	_, rest, _ := strings.Cut(n.Raw, "\n")
+	first := formatter.Concat(formatter.Text(assignmentHead(n, before)), formatter.Text(after+value))
+
+	clone := n.Clone()
+	clone.Raw = formatter.Render(formatter.Concat(first, formatter.HardLine(), formatter.Text(rest)), 0, 0)
+	return clone
+}
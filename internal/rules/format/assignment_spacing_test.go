@@ -98,3 +98,52 @@ func TestAssignmentSpacingPreserve(t *testing.T) {
 		t.Error("preserve mode should return same node pointer")
 	}
 }
+
+func TestAssignmentSpacingModifiers(t *testing.T) {
+	rule := &AssignmentSpacing{}
+
+	tests := []struct {
+		mode string
+		want string
+	}{
+		{"space", "export override CFLAGS += -g\n"},
+		{"no_space", "export override CFLAGS+=-g\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.mode, func(t *testing.T) {
+			cfg := &config.DefaultConfig().Formatter
+			cfg.AssignmentSpacing = tt.mode
+
+			nodes := parser.Parse("export  override CFLAGS+= -g\n")
+			if got := formatter.Write(rule.Format(nodes, cfg)); got != tt.want {
+				t.Errorf("want %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestAssignmentSpacingContinuation(t *testing.T) {
+	rule := &AssignmentSpacing{}
+
+	tests := []struct {
+		mode  string
+		input string
+		want  string
+	}{
+		{"space", "SRCS:=a.c \\\n\tb.c # all\n", "SRCS := a.c \\\n\tb.c # all\n"},
+		{"space", "export SRCS  :=\\\n  a.c\n", "export SRCS := \\\n  a.c\n"},
+		{"no_space", "SRCS := a.c \\\n  b.c\n", "SRCS:=a.c \\\n  b.c\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.mode, func(t *testing.T) {
+			cfg := &config.DefaultConfig().Formatter
+			cfg.AssignmentSpacing = tt.mode
+
+			if got := formatter.Write(rule.Format(parser.Parse(tt.input), cfg)); got != tt.want {
+				t.Errorf("want %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"strings"
 
 	"github.com/donaldgifford/makefmt/internal/config"
+	"github.com/donaldgifford/makefmt/internal/formatter"
 	"github.com/donaldgifford/makefmt/internal/parser"
 )
 
@@ -16,12 +17,31 @@ func (*BackslashAlign) Name() string {
 	return "align_backslash_continuations"
 }
 
-// Format aligns trailing backslashes in continuation lines.
-func (*BackslashAlign) Format(nodes []*parser.Node, cfg *config.FormatterConfig) []*parser.Node {
-	if !cfg.AlignBackslashContinuations {
+// Description returns a one-line summary of the rule.
+func (*BackslashAlign) Description() string {
+	return "Aligns trailing backslashes in continuation blocks."
+}
+
+// Active reports whether align_backslash_continuations is set.
+func (*BackslashAlign) Active(cfg *config.FormatterConfig) bool {
+	return cfg.AlignBackslashContinuations
+}
+
+// Format aligns trailing backslashes in continuation lines. Recipe lines,
+// which are rule children, are left alone. It runs after
+// ConditionalIndent, so columns are measured on the indented text. With
+// max_line_length set, backslashes never go past the line length, so
+// aligning does not undo LineWrap.
+func (r *BackslashAlign) Format(nodes []*parser.Node, cfg *config.FormatterConfig) []*parser.Node {
+	if !r.Active(cfg) {
 		return nodes
 	}
+	return alignNodes(nodes, cfg.MaxLineLength, cfg.BackslashColumn, cfg.TabWidth)
+}
 
+// alignNodes aligns the backslashes of each node, keeping them within
+// width columns when that is positive.
+func alignNodes(nodes []*parser.Node, width, backslashCol, tabWidth int) []*parser.Node {
 	result := make([]*parser.Node, len(nodes))
 	copy(result, nodes)
 
@@ -31,7 +51,7 @@ func (*BackslashAlign) Format(nodes []*parser.Node, cfg *config.FormatterConfig)
 		}
 
 		// Process each continuation block in the node's Raw field.
-		result[i] = alignBackslashes(n, cfg.BackslashColumn)
+		result[i] = alignBackslashes(n, width, backslashCol, tabWidth)
 	}
 
 	return result
@@ -50,8 +70,9 @@ func hasContinuation(raw string) bool {
 }
 
 // alignBackslashes clones the node and aligns all trailing backslashes
-// in its Raw field to the target column.
-func alignBackslashes(n *parser.Node, backslashCol int) *parser.Node {
+// in its Raw field to the target display column, which is at most width
+// when width is positive.
+func alignBackslashes(n *parser.Node, width, backslashCol, tabWidth int) *parser.Node {
 	clone := n.Clone()
 	lines := strings.Split(clone.Raw, "\n")
 
@@ -65,9 +86,7 @@ func alignBackslashes(n *parser.Node, backslashCol int) *parser.Node {
 		}
 		// Content width = everything before the trailing backslash.
 		content := strings.TrimRight(trimmed[:len(trimmed)-1], " \t")
-		if len(content) > maxContentWidth {
-			maxContentWidth = len(content)
-		}
+		maxContentWidth = max(maxContentWidth, formatter.Width(content, tabWidth))
 	}
 
 	// Determine the target column.
@@ -76,20 +95,26 @@ func alignBackslashes(n *parser.Node, backslashCol int) *parser.Node {
 		// Auto mode: longest content + 1 space + backslash.
 		targetCol = maxContentWidth + 2
 	}
+	if width > 0 {
+		targetCol = min(targetCol, width)
+	}
 
-	// Align each continuation line.
+	// Lay the node out again with each continuation line aligned; other
+	// lines keep their text.
+	docs := make([]formatter.Doc, len(lines))
 	for i, line := range lines {
 		trimmed := strings.TrimRight(line, " \t")
 		if !strings.HasSuffix(trimmed, "\\") {
+			docs[i] = formatter.Text(line)
 			continue
 		}
 
 		content := strings.TrimRight(trimmed[:len(trimmed)-1], " \t")
-		// Pad content to targetCol - 1 (the backslash goes at targetCol).
-		padWidth := max(targetCol-1-len(content), 1) // Always at least one space before \.
-		lines[i] = content + strings.Repeat(" ", padWidth) + "\\"
+		// Pad content to targetCol - 1 (the backslash goes at targetCol);
+		// Align always writes at least one space before \.
+		docs[i] = formatter.Concat(formatter.Text(content), formatter.Align(targetCol-1), formatter.Text("\\"))
 	}
 
-	clone.Raw = strings.Join(lines, "\n")
+	clone.Raw = formatter.Render(formatter.Join(formatter.HardLine(), docs), 0, tabWidth)
 	return clone
 }
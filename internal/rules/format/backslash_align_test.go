@@ -85,6 +85,27 @@ func TestBackslashAlignDisabled(t *testing.T) {
 	}
 }
 
+func TestBackslashAlignDisplayWidth(t *testing.T) {
+	rule := &BackslashAlign{}
+	cfg := &config.DefaultConfig().Formatter
+	cfg.BackslashColumn = 0
+	cfg.TabWidth = 4
+
+	node := &parser.Node{
+		Type: parser.NodeRaw,
+		Raw:  "\techo \"héllo\" \\\n\t\techo 日本 \\\nx",
+	}
+
+	result := rule.Format([]*parser.Node{node}, cfg)
+
+	// With tab stops every 4 columns the lines are 16 and 17 columns
+	// wide, though the second has fewer bytes and runes.
+	want := "\techo \"héllo\"  \\\n\t\techo 日本 \\\nx"
+	if result[0].Raw != want {
+		t.Errorf("want %q, got %q", want, result[0].Raw)
+	}
+}
+
 func TestBackslashAlignNoContinuation(t *testing.T) {
 	rule := &BackslashAlign{}
 	cfg := &config.DefaultConfig().Formatter
@@ -15,6 +15,16 @@ func (*BannerPreserve) Name() string {
 	return "preserve_banner_comments"
 }
 
+// Description returns a one-line summary of the rule.
+func (*BannerPreserve) Description() string {
+	return "Keeps banner comments and ##@ section headers unchanged."
+}
+
+// Active always reports true: the rule has no setting of its own.
+func (*BannerPreserve) Active(*config.FormatterConfig) bool {
+	return true
+}
+
 // Format restores banner comments and section headers to their original
 // Raw form if any prior rule modified them.
 func (*BannerPreserve) Format(nodes []*parser.Node, _ *config.FormatterConfig) []*parser.Node {
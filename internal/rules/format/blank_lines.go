@@ -13,9 +13,19 @@ func (*BlankLines) Name() string {
 	return "max_blank_lines"
 }
 
+// Description returns a one-line summary of the rule.
+func (*BlankLines) Description() string {
+	return "Collapses runs of blank lines to max_blank_lines."
+}
+
+// Active reports whether max_blank_lines is not negative.
+func (*BlankLines) Active(cfg *config.FormatterConfig) bool {
+	return cfg.MaxBlankLines >= 0
+}
+
 // Format collapses runs of blank lines to at most cfg.MaxBlankLines.
-func (*BlankLines) Format(nodes []*parser.Node, cfg *config.FormatterConfig) []*parser.Node {
-	if cfg.MaxBlankLines < 0 {
+func (r *BlankLines) Format(nodes []*parser.Node, cfg *config.FormatterConfig) []*parser.Node {
+	if !r.Active(cfg) {
 		return nodes
 	}
 
@@ -4,6 +4,7 @@ import (
 	"strings"
 
 	"github.com/donaldgifford/makefmt/internal/config"
+	"github.com/donaldgifford/makefmt/internal/formatter"
 	"github.com/donaldgifford/makefmt/internal/parser"
 )
 
@@ -15,17 +16,37 @@ func (*CommentSpacing) Name() string {
 	return "space_after_comment"
 }
 
-// Format normalizes spacing after # in comment nodes.
-func (*CommentSpacing) Format(nodes []*parser.Node, cfg *config.FormatterConfig) []*parser.Node {
-	if !cfg.SpaceAfterComment {
+// Description returns a one-line summary of the rule.
+func (*CommentSpacing) Description() string {
+	return "Ensures a space after # in single-hash comments."
+}
+
+// Active reports whether space_after_comment is set.
+func (*CommentSpacing) Active(cfg *config.FormatterConfig) bool {
+	return cfg.SpaceAfterComment
+}
+
+// Format normalizes spacing after # in comment nodes, including Make
+// comments between recipe lines. Shell comments in recipes are recipe
+// text and are left alone.
+func (r *CommentSpacing) Format(nodes []*parser.Node, cfg *config.FormatterConfig) []*parser.Node {
+	if !r.Active(cfg) {
 		return nodes
 	}
+	return spaceComments(nodes)
+}
 
+func spaceComments(nodes []*parser.Node) []*parser.Node {
 	result := make([]*parser.Node, len(nodes))
 	for i, n := range nodes {
-		if n.Type == parser.NodeComment && shouldNormalize(n) {
+		switch {
+		case n.Type == parser.NodeComment && shouldNormalize(n):
 			result[i] = normalizeComment(n)
-		} else {
+		case n.Type == parser.NodeRule && len(n.Children) > 0:
+			clone := *n
+			clone.Children = spaceComments(n.Children)
+			result[i] = &clone
+		default:
 			result[i] = n
 		}
 	}
@@ -63,9 +84,19 @@ func normalizeComment(n *parser.Node) *parser.Node {
 		return n
 	}
 
-	// Insert space after #.
 	clone := n.Clone()
-	clone.Raw = "# " + raw[1:]
-	clone.Fields.Text = strings.TrimSpace(raw[1:])
+	first, rest, continued := strings.Cut(raw, "\n")
+	if !continued {
+		// The parsed text has no leading space, so clearing Raw lets the
+		// writer lay the comment out as "# text".
+		clone.Raw = ""
+		return clone
+	}
+
+	// A comment continued with a backslash gets the space on its first
+	// line only; the continuation lines are kept as written.
+	text := strings.TrimLeft(strings.TrimPrefix(first, n.Fields.Prefix), " \t")
+	clone.Raw = formatter.Render(formatter.Concat(
+		formatter.Text(n.Fields.Prefix+" "+text), formatter.HardLine(), formatter.Text(rest)), 0, 0)
 	return clone
 }
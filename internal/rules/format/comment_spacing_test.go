@@ -4,6 +4,7 @@ import (
 	"testing"
 
 	"github.com/donaldgifford/makefmt/internal/config"
+	"github.com/donaldgifford/makefmt/internal/formatter"
 	"github.com/donaldgifford/makefmt/internal/parser"
 )
 
@@ -21,8 +22,8 @@ func TestCommentSpacingAddsSpace(t *testing.T) {
 	}
 
 	result := rule.Format([]*parser.Node{node}, cfg)
-	if result[0].Raw != "# comment" {
-		t.Errorf("want %q, got %q", "# comment", result[0].Raw)
+	if got := formatter.Write(result); got != "# comment\n" {
+		t.Errorf("want %q, got %q", "# comment\n", got)
 	}
 }
 
@@ -121,3 +122,36 @@ func TestCommentSpacingDisabled(t *testing.T) {
 		t.Error("disabled rule should not modify nodes")
 	}
 }
+
+func TestCommentSpacingRecipeComments(t *testing.T) {
+	rule := &CommentSpacing{}
+	cfg := &config.DefaultConfig().Formatter
+
+	nodes := parser.Parse("all:\n\t#shell comment\n#make comment\n\techo hi\n")
+	result := rule.Format(nodes, cfg)
+
+	if len(result) != 1 || len(result[0].Children) != 3 {
+		t.Fatalf("expected one rule with 3 children, got %d nodes", len(result))
+	}
+	children := result[0].Children
+	if children[0].Raw != "\t#shell comment" {
+		t.Errorf("shell comment: want %q, got %q", "\t#shell comment", children[0].Raw)
+	}
+	if got := formatter.Write(children[1:2]); got != "# make comment\n" {
+		t.Errorf("make comment: want %q, got %q", "# make comment\n", got)
+	}
+	if nodes[0].Children[1].Raw != "#make comment" {
+		t.Error("input nodes were mutated")
+	}
+}
+
+func TestCommentSpacingContinuedComment(t *testing.T) {
+	rule := &CommentSpacing{}
+	cfg := &config.DefaultConfig().Formatter
+
+	input := "#foo \\\n  bar\n"
+	want := "# foo \\\n  bar\n"
+	if got := formatter.Write(rule.Format(parser.Parse(input), cfg)); got != want {
+		t.Errorf("want %q, got %q", want, got)
+	}
+}
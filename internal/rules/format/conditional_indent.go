@@ -4,6 +4,7 @@ import (
 	"strings"
 
 	"github.com/donaldgifford/makefmt/internal/config"
+	"github.com/donaldgifford/makefmt/internal/formatter"
 	"github.com/donaldgifford/makefmt/internal/parser"
 )
 
@@ -21,47 +22,59 @@ func (*ConditionalIndent) Name() string {
 	return "indent_conditionals"
 }
 
+// Description returns a one-line summary of the rule.
+func (*ConditionalIndent) Description() string {
+	return "Indents the bodies of ifeq/ifneq/ifdef/ifndef blocks."
+}
+
+// Active reports whether indent_conditionals is set with a positive
+// conditional_indent.
+func (*ConditionalIndent) Active(cfg *config.FormatterConfig) bool {
+	return cfg.IndentConditionals && cfg.ConditionalIndent > 0
+}
+
 // Format applies indentation to conditional block bodies.
-func (*ConditionalIndent) Format(nodes []*parser.Node, cfg *config.FormatterConfig) []*parser.Node {
-	if !cfg.IndentConditionals || cfg.ConditionalIndent <= 0 {
+func (r *ConditionalIndent) Format(nodes []*parser.Node, cfg *config.FormatterConfig) []*parser.Node {
+	if !r.Active(cfg) {
 		return nodes
 	}
 
 	indent := strings.Repeat(" ", cfg.ConditionalIndent)
-	return indentConditionals(nodes, indent, 0)
+	return indentConditionals(nodes, indent)
+}
+
+// indentConditionals applies indentation based on conditional nesting
+// level.
+func indentConditionals(nodes []*parser.Node, indent string) []*parser.Node {
+	result := make([]*parser.Node, len(nodes))
+	for i, level := range conditionalLevels(nodes) {
+		result[i] = applyIndent(nodes[i], indent, level)
+	}
+	return result
 }
 
-// indentConditionals walks nodes and applies indentation based on
-// conditional nesting level.
-func indentConditionals(nodes []*parser.Node, indent string, level int) []*parser.Node {
-	result := make([]*parser.Node, 0, len(nodes))
+// conditionalLevels returns the number of indents ConditionalIndent
+// gives each node.
+func conditionalLevels(nodes []*parser.Node) []int {
+	levels := make([]int, len(nodes))
+	level := 0
 
-	for _, n := range nodes {
+	for i, n := range nodes {
 		switch {
 		case n.Type == parser.NodeConditional && isConditionalOpen(n.Fields.Directive):
-			result = append(result, applyIndent(n, indent, level))
+			levels[i] = level
 			level++
-
 		case n.Type == parser.NodeConditional && n.Fields.Directive == directiveElse:
-			result = append(result, applyIndent(n, indent, level-1))
-
+			levels[i] = max(level-1, 0)
 		case n.Type == parser.NodeConditional && n.Fields.Directive == directiveEndif:
-			level--
-			if level < 0 {
-				level = 0
-			}
-			result = append(result, applyIndent(n, indent, level))
-
+			level = max(level-1, 0)
+			levels[i] = level
 		default:
-			if level > 0 {
-				result = append(result, applyIndent(n, indent, level))
-			} else {
-				result = append(result, n)
-			}
+			levels[i] = level
 		}
 	}
 
-	return result
+	return levels
 }
 
 // isConditionalOpen returns true for directives that open a conditional block.
@@ -73,69 +86,21 @@ func isConditionalOpen(directive string) bool {
 	return false
 }
 
-// applyIndent prepends the given indent to the node. If Raw is empty
-// (cleared by a prior rule), it reconstructs Raw from fields first.
+// applyIndent replaces the node's leading indentation with the given
+// indent. If Raw is empty (cleared by a prior rule), the line is laid out
+// from the node's fields.
 func applyIndent(n *parser.Node, indent string, level int) *parser.Node {
 	if level <= 0 {
 		return n
 	}
 
-	prefix := strings.Repeat(indent, level)
 	clone := n.Clone()
 
-	raw := clone.Raw
-	if raw == "" {
-		raw = reconstructRaw(clone)
+	body := formatter.NodeDoc(clone)
+	if clone.Raw != "" {
+		body = formatter.Text(strings.TrimLeft(clone.Raw, " "))
 	}
-	clone.Raw = prefix + raw
+	clone.Raw = formatter.Render(formatter.Concat(formatter.Text(strings.Repeat(indent, level)), body), 0, 0)
 
 	return clone
 }
-
-// reconstructRaw produces the text representation of a node from its
-// fields, mirroring what the writer would emit. This is needed when a
-// prior rule cleared Raw (e.g., assignment spacing normalizes Raw).
-func reconstructRaw(n *parser.Node) string {
-	switch n.Type {
-	case parser.NodeAssignment:
-		s := n.Fields.VarName + " " + n.Fields.AssignOp
-		if n.Fields.VarValue != "" {
-			s += " " + n.Fields.VarValue
-		}
-		return s
-
-	case parser.NodeComment:
-		if n.Fields.Text != "" {
-			return n.Fields.Prefix + " " + n.Fields.Text
-		}
-		return n.Fields.Prefix
-
-	case parser.NodeConditional:
-		if n.Fields.Condition != "" {
-			return n.Fields.Directive + " " + n.Fields.Condition
-		}
-		return n.Fields.Directive
-
-	case parser.NodeInclude:
-		if len(n.Fields.Paths) > 0 {
-			return n.Fields.IncludeType + " " + strings.Join(n.Fields.Paths, " ")
-		}
-		return n.Fields.IncludeType
-
-	case parser.NodeRule:
-		s := strings.Join(n.Fields.Targets, " ") + ":"
-		if len(n.Fields.Prerequisites) > 0 {
-			s += " " + strings.Join(n.Fields.Prerequisites, " ")
-		}
-		if n.Fields.InlineHelp != "" {
-			s += " ## " + n.Fields.InlineHelp
-		}
-		return s
-
-	case parser.NodeBlankLine:
-		return ""
-
-	default:
-		return n.Fields.Text
-	}
-}
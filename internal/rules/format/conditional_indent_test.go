@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	"github.com/donaldgifford/makefmt/internal/config"
+	"github.com/donaldgifford/makefmt/internal/formatter"
 	"github.com/donaldgifford/makefmt/internal/parser"
 )
 
@@ -111,3 +112,47 @@ func TestConditionalIndentDisabled(t *testing.T) {
 		t.Error("disabled rule should not indent")
 	}
 }
+
+func TestConditionalIndentFromFields(t *testing.T) {
+	rule := &ConditionalIndent{}
+	cfg := &config.DefaultConfig().Formatter
+
+	nodes := []*parser.Node{
+		{Type: parser.NodeConditional, Raw: "ifdef DEBUG", Fields: parser.NodeFields{Directive: "ifdef", Condition: "DEBUG"}},
+		{Type: parser.NodeRule, Fields: parser.NodeFields{
+			Targets:       []string{"build"},
+			Prerequisites: []string{"main.o"},
+			OrderOnly:     []string{"bin"},
+			InlineComment: "# debug",
+		}},
+		{Type: parser.NodeConditional, Raw: "endif", Fields: parser.NodeFields{Directive: "endif"}},
+	}
+
+	result := rule.Format(nodes, cfg)
+
+	if want := "  build: main.o | bin # debug"; result[1].Raw != want {
+		t.Errorf("want %q, got %q", want, result[1].Raw)
+	}
+}
+
+func TestConditionalIndentIdempotent(t *testing.T) {
+	rule := &ConditionalIndent{}
+	cfg := &config.DefaultConfig().Formatter
+
+	src := "ifdef DEBUG\n" +
+		"unexport CC\n" +
+		"    # comment\n" +
+		"endif\n"
+	want := "ifdef DEBUG\n" +
+		"  unexport CC\n" +
+		"  # comment\n" +
+		"endif\n"
+
+	once := formatter.Write(rule.Format(parser.Parse(src), cfg))
+	if once != want {
+		t.Fatalf("first pass:\nwant %q\ngot  %q", want, once)
+	}
+	if twice := formatter.Write(rule.Format(parser.Parse(once), cfg)); twice != once {
+		t.Errorf("second pass changed output:\nwant %q\ngot  %q", once, twice)
+	}
+}
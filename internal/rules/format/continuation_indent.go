@@ -0,0 +1,135 @@
+package format
+
+import (
+	"strings"
+
+	"github.com/donaldgifford/makefmt/internal/config"
+	"github.com/donaldgifford/makefmt/internal/formatter"
+	"github.com/donaldgifford/makefmt/internal/parser"
+)
+
+// ContinuationIndent sets the leading indentation of continuation lines
+// to a hanging indent of continuation_indent spaces.
+type ContinuationIndent struct{}
+
+// Name returns the config key for this rule.
+func (*ContinuationIndent) Name() string {
+	return "indent_continuations"
+}
+
+// Description returns a one-line summary of the rule.
+func (*ContinuationIndent) Description() string {
+	return "Indents continuation lines by continuation_indent spaces past their first line."
+}
+
+// Active reports whether indent_continuations is set.
+func (*ContinuationIndent) Active(cfg *config.FormatterConfig) bool {
+	return cfg.IndentContinuations
+}
+
+// Format reindents continuation lines. Assignment, rule and directive
+// continuations hang from the first line, including the indentation
+// ConditionalIndent gives it. Recipe continuations keep their leading
+// tab and are indented past it.
+func (r *ContinuationIndent) Format(nodes []*parser.Node, cfg *config.FormatterConfig) []*parser.Node {
+	if !r.Active(cfg) {
+		return nodes
+	}
+
+	indent := strings.Repeat(" ", cfg.ContinuationIndent)
+	result := make([]*parser.Node, len(nodes))
+
+	for i, level := range conditionalLevels(nodes) {
+		n := nodes[i]
+
+		first := leadingSpace(n.Raw)
+		if cfg.IndentConditionals && level > 0 {
+			first = strings.Repeat(" ", level*cfg.ConditionalIndent)
+		}
+		n = indentContinuations(n, first+indent)
+
+		if n.Type == parser.NodeRule && len(n.Children) > 0 {
+			clone := *n
+			clone.Children = make([]*parser.Node, len(n.Children))
+			for j, child := range n.Children {
+				clone.Children[j] = indentContinuations(child, "\t"+indent)
+			}
+			n = &clone
+		}
+		result[i] = n
+	}
+
+	return result
+}
+
+// indentContinuations returns n with each continuation line of its Raw
+// text indented by hanging. Comments, raw text, and rules with inline
+// recipes are left alone. In recipes, a line that continues a quoted
+// shell string is left alone too, since its whitespace is part of the
+// string.
+func indentContinuations(n *parser.Node, hanging string) *parser.Node {
+	if !strings.Contains(n.Raw, "\n") {
+		return n
+	}
+
+	recipe := n.Type == parser.NodeRecipe
+	switch {
+	case n.Type == parser.NodeComment, n.Type == parser.NodeSectionHeader,
+		n.Type == parser.NodeBannerComment, n.Type == parser.NodeRaw:
+		return n
+	case n.Type == parser.NodeRecipe && n.Fields.ShellComment:
+		return n
+	case n.Type == parser.NodeRule && n.Fields.InlineRecipe != "":
+		return n
+	}
+
+	lines := strings.Split(n.Raw, "\n")
+	docs := []formatter.Doc{formatter.Text(lines[0])}
+	var quote byte
+	for i := 1; i < len(lines); i++ {
+		line := formatter.Concat(formatter.HardLine(), formatter.Text(lines[i]))
+		if recipe {
+			quote = shellQuote(lines[i-1], quote)
+		}
+		if text := strings.TrimLeft(lines[i], " \t"); text != "" && quote == 0 {
+			line = formatter.Indent(hanging, formatter.Concat(formatter.HardLine(), formatter.Text(text)))
+		}
+		docs = append(docs, line)
+	}
+
+	raw := formatter.Render(formatter.Concat(docs...), 0, 0)
+	if raw == n.Raw {
+		return n
+	}
+	clone := n.Clone()
+	clone.Raw = raw
+	return clone
+}
+
+// shellQuote returns the shell quote (' or ") still open at the end of
+// line, given the quote open at its start.
+func shellQuote(line string, quote byte) byte {
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case quote == '\'':
+			if c == '\'' {
+				quote = 0
+			}
+		case c == '\\':
+			i++ // Skip the escaped character.
+		case quote == '"':
+			if c == '"' {
+				quote = 0
+			}
+		case c == '\'' || c == '"':
+			quote = c
+		}
+	}
+	return quote
+}
+
+// leadingSpace returns the spaces and tabs that start s.
+func leadingSpace(s string) string {
+	return s[:len(s)-len(strings.TrimLeft(s, " \t"))]
+}
@@ -0,0 +1,112 @@
+package format
+
+import (
+	"testing"
+
+	"github.com/donaldgifford/makefmt/internal/config"
+	"github.com/donaldgifford/makefmt/internal/formatter"
+	"github.com/donaldgifford/makefmt/internal/parser"
+)
+
+func TestContinuationIndent(t *testing.T) {
+	tests := []struct {
+		name  string
+		setup func(cfg *config.FormatterConfig)
+		input string
+		want  string
+	}{
+		{
+			name:  "assignment",
+			input: "SRCS := a.c \\\n\tb.c \\\n        c.c\n",
+			want:  "SRCS := a.c \\\n    b.c \\\n    c.c\n",
+		},
+		{
+			name:  "rule prerequisites",
+			input: "all: a \\\n  b\n",
+			want:  "all: a \\\n    b\n",
+		},
+		{
+			name:  "hangs from conditional indent",
+			input: "ifdef X\nFLAGS = -a \\\n-b\nendif\n",
+			want:  "ifdef X\nFLAGS = -a \\\n      -b\nendif\n",
+		},
+		{
+			name: "hangs from first line",
+			setup: func(cfg *config.FormatterConfig) {
+				cfg.IndentConditionals = false
+			},
+			input: "  FLAGS = -a \\\n-b\n",
+			want:  "  FLAGS = -a \\\n      -b\n",
+		},
+		{
+			name:  "recipe keeps tab",
+			input: "all:\n\techo a \\\n\t\t\tb \\\n  c\n",
+			want:  "all:\n\techo a \\\n\t    b \\\n\t    c\n",
+		},
+		{
+			name:  "quoted recipe text kept",
+			input: "all:\n\techo 'a \\\n  b' \\\n  c\n",
+			want:  "all:\n\techo 'a \\\n  b' \\\n\t    c\n",
+		},
+		{
+			name:  "define body kept",
+			input: "define X\n  a \\\n b\nendef\n",
+			want:  "define X\n  a \\\n b\nendef\n",
+		},
+		{
+			name: "custom indent",
+			setup: func(cfg *config.FormatterConfig) {
+				cfg.ContinuationIndent = 2
+			},
+			input: "SRCS := a.c \\\n\tb.c\n",
+			want:  "SRCS := a.c \\\n  b.c\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.DefaultConfig().Formatter
+			cfg.IndentContinuations = true
+			if tt.setup != nil {
+				tt.setup(cfg)
+			}
+
+			got := formatter.Write((&ContinuationIndent{}).Format(parser.Parse(tt.input), cfg))
+			if got != tt.want {
+				t.Errorf("want %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestContinuationIndentDisabled(t *testing.T) {
+	cfg := &config.DefaultConfig().Formatter
+
+	input := "SRCS := a.c \\\n\tb.c\n"
+	if got := formatter.Write((&ContinuationIndent{}).Format(parser.Parse(input), cfg)); got != input {
+		t.Errorf("want %q, got %q", input, got)
+	}
+}
+
+func TestShellQuote(t *testing.T) {
+	tests := []struct {
+		line  string
+		quote byte
+		want  byte
+	}{
+		{"echo a", 0, 0},
+		{"echo 'a", 0, '\''},
+		{`echo "a`, 0, '"'},
+		{`echo "it's`, 0, '"'},
+		{`echo 'say "hi`, 0, '\''},
+		{`echo \'a`, 0, 0},
+		{"b' c", '\'', 0},
+		{`b\" c`, '"', '"'},
+	}
+
+	for _, tt := range tests {
+		if got := shellQuote(tt.line, tt.quote); got != tt.want {
+			t.Errorf("shellQuote(%q, %q) = %q, want %q", tt.line, tt.quote, got, tt.want)
+		}
+	}
+}
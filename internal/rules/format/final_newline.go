@@ -16,10 +16,20 @@ func (*FinalNewline) Name() string {
 	return "insert_final_newline"
 }
 
+// Description returns a one-line summary of the rule.
+func (*FinalNewline) Description() string {
+	return "Ends the file with exactly one newline."
+}
+
+// Active reports whether insert_final_newline is set.
+func (*FinalNewline) Active(cfg *config.FormatterConfig) bool {
+	return cfg.InsertFinalNewline
+}
+
 // Format removes trailing blank lines so the writer produces exactly
 // one final newline.
-func (*FinalNewline) Format(nodes []*parser.Node, cfg *config.FormatterConfig) []*parser.Node {
-	if !cfg.InsertFinalNewline {
+func (r *FinalNewline) Format(nodes []*parser.Node, cfg *config.FormatterConfig) []*parser.Node {
+	if !r.Active(cfg) {
 		return nodes
 	}
 
@@ -0,0 +1,96 @@
+package format
+
+import (
+	"strings"
+
+	"github.com/donaldgifford/makefmt/internal/config"
+	"github.com/donaldgifford/makefmt/internal/formatter"
+	"github.com/donaldgifford/makefmt/internal/parser"
+)
+
+// InlineCommentAlign aligns the trailing comments of consecutive lines
+// into one column.
+type InlineCommentAlign struct{}
+
+// Name returns the config key for this rule.
+func (*InlineCommentAlign) Name() string {
+	return "align_inline_comments"
+}
+
+// Description returns a one-line summary of the rule.
+func (*InlineCommentAlign) Description() string {
+	return "Aligns trailing comments on consecutive lines into one column."
+}
+
+// Active reports whether align_inline_comments is set.
+func (*InlineCommentAlign) Active(cfg *config.FormatterConfig) bool {
+	return cfg.AlignInlineComments
+}
+
+// Format aligns inline comments in each run of consecutive single-line
+// nodes that have one. A rule with recipe lines ends a run.
+func (r *InlineCommentAlign) Format(nodes []*parser.Node, cfg *config.FormatterConfig) []*parser.Node {
+	if !r.Active(cfg) {
+		return nodes
+	}
+
+	result := make([]*parser.Node, len(nodes))
+	copy(result, nodes)
+
+	for start := 0; start < len(result); {
+		end := start
+		for end < len(result) {
+			if _, ok := commentContent(result[end]); !ok {
+				break
+			}
+			end++
+			if len(result[end-1].Children) > 0 {
+				break
+			}
+		}
+		if end-start > 1 {
+			alignComments(result[start:end], cfg.TabWidth)
+		}
+		start = max(end, start+1)
+	}
+
+	return result
+}
+
+// alignComments pads the content of each node so that the comments all
+// start one display column after the longest content.
+func alignComments(run []*parser.Node, tabWidth int) {
+	contents := make([]string, len(run))
+	width := 0
+	for i, n := range run {
+		contents[i], _ = commentContent(n)
+		width = max(width, formatter.Width(contents[i], tabWidth))
+	}
+
+	for i, n := range run {
+		clone := n.Clone()
+		line := formatter.Render(formatter.Concat(formatter.Text(contents[i]), formatter.Align(width+1)), 0, tabWidth)
+		clone.Fields.CommentSpace = line[len(contents[i]):]
+		clone.Raw = formatter.Render(formatter.Concat(formatter.Text(contents[i]), formatter.CommentDoc(clone)), 0, tabWidth)
+		run[i] = clone
+	}
+}
+
+// commentContent returns the text of a single-line node before its
+// inline comment. It reports false if the node has no comment to align.
+func commentContent(n *parser.Node) (string, bool) {
+	if n.Fields.InlineComment == "" || strings.Contains(n.Raw, "\n") {
+		return "", false
+	}
+
+	if n.Raw == "" {
+		return formatter.Render(formatter.ContentDoc(n), 0, 0), true
+	}
+
+	raw := strings.TrimRight(n.Raw, " \t")
+	before, ok := strings.CutSuffix(raw, n.Fields.InlineComment)
+	if !ok {
+		return "", false
+	}
+	return strings.TrimRight(before, " \t"), true
+}
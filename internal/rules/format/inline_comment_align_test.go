@@ -0,0 +1,71 @@
+package format
+
+import (
+	"testing"
+
+	"github.com/donaldgifford/makefmt/internal/config"
+	"github.com/donaldgifford/makefmt/internal/formatter"
+	"github.com/donaldgifford/makefmt/internal/parser"
+)
+
+func TestInlineCommentAlign(t *testing.T) {
+	rule := &InlineCommentAlign{}
+	cfg := &config.DefaultConfig().Formatter
+	cfg.AlignInlineComments = true
+
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{
+			name:  "consecutive lines",
+			input: "A := 1 # one\nLONGER := 2   # two\ninclude x.mk # three\n",
+			want:  "A := 1       # one\nLONGER := 2  # two\ninclude x.mk # three\n",
+		},
+		{
+			name:  "line without comment ends the run",
+			input: "A := 1 # one\nB := 2\nLONGER := 3 # three\nC := 4 # four\n",
+			want:  "A := 1 # one\nB := 2\nLONGER := 3 # three\nC := 4      # four\n",
+		},
+		{
+			name:  "rule with recipe ends the run",
+			input: "build: a # one\n\techo\nLONGER := 3 # three\n",
+			want:  "build: a # one\n\techo\nLONGER := 3 # three\n",
+		},
+		{
+			name:  "wide characters",
+			input: "GREETING := 日本 # wide\nNAME := abcd # ascii\n",
+			want:  "GREETING := 日本 # wide\nNAME := abcd     # ascii\n",
+		},
+		{
+			name:  "continuation lines are skipped",
+			input: "A := 1 # one\nB := x \\\n  y # two\n",
+			want:  "A := 1 # one\nB := x \\\n  y # two\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := formatter.Write(rule.Format(parser.Parse(tt.input), cfg))
+			if got != tt.want {
+				t.Errorf("want %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestInlineCommentAlignReconstructed(t *testing.T) {
+	cfg := &config.DefaultConfig().Formatter
+	cfg.AlignInlineComments = true
+
+	// Assignment spacing clears Raw, so the content is rebuilt from fields.
+	nodes := parser.Parse("A:=1 # one\nLONGER:=2 # two\n")
+	nodes = (&AssignmentSpacing{}).Format(nodes, cfg)
+	got := formatter.Write((&InlineCommentAlign{}).Format(nodes, cfg))
+
+	want := "A := 1      # one\nLONGER := 2 # two\n"
+	if got != want {
+		t.Errorf("want %q, got %q", want, got)
+	}
+}
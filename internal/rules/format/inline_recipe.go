@@ -0,0 +1,66 @@
+package format
+
+import (
+	"strings"
+
+	"github.com/donaldgifford/makefmt/internal/config"
+	"github.com/donaldgifford/makefmt/internal/parser"
+)
+
+// InlineRecipe moves recipes written after ";" on a rule line onto a
+// tab-indented recipe line of their own.
+type InlineRecipe struct{}
+
+// Name returns the config key for this rule.
+func (*InlineRecipe) Name() string {
+	return "expand_inline_recipes"
+}
+
+// Description returns a one-line summary of the rule.
+func (*InlineRecipe) Description() string {
+	return "Moves inline recipes (target: ; command) onto their own recipe line."
+}
+
+// Active reports whether expand_inline_recipes is set.
+func (*InlineRecipe) Active(cfg *config.FormatterConfig) bool {
+	return cfg.ExpandInlineRecipes
+}
+
+// Format expands inline recipes when enabled. Rule lines with
+// continuation lines are left alone, since a backslash-newline in a
+// recipe is passed to the shell.
+func (r *InlineRecipe) Format(nodes []*parser.Node, cfg *config.FormatterConfig) []*parser.Node {
+	if !r.Active(cfg) {
+		return nodes
+	}
+
+	result := make([]*parser.Node, len(nodes))
+	for i, n := range nodes {
+		if n.Type == parser.NodeRule && n.Fields.InlineRecipe != "" && !strings.Contains(n.Raw, "\n") {
+			result[i] = expandInlineRecipe(n)
+		} else {
+			result[i] = n
+		}
+	}
+	return result
+}
+
+// expandInlineRecipe returns a copy of the rule with its inline recipe as
+// the first recipe line.
+func expandInlineRecipe(n *parser.Node) *parser.Node {
+	clone := n.Clone()
+
+	recipe := &parser.Node{
+		Type: parser.NodeRecipe,
+		Line: n.Line,
+		Fields: parser.NodeFields{
+			Text: clone.Fields.InlineRecipe,
+		},
+	}
+	clone.Children = append([]*parser.Node{recipe}, clone.Children...)
+
+	// Clear Raw so the writer rebuilds the rule line without the recipe.
+	clone.Fields.InlineRecipe = ""
+	clone.Raw = ""
+	return clone
+}
@@ -0,0 +1,72 @@
+package format
+
+import (
+	"testing"
+
+	"github.com/donaldgifford/makefmt/internal/config"
+	"github.com/donaldgifford/makefmt/internal/formatter"
+	"github.com/donaldgifford/makefmt/internal/parser"
+)
+
+func TestInlineRecipe(t *testing.T) {
+	rule := &InlineRecipe{}
+	cfg := &config.DefaultConfig().Formatter
+	cfg.ExpandInlineRecipes = true
+
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{
+			name:  "no prerequisites",
+			input: "clean: ; rm -rf build\n",
+			want:  "clean:\n\trm -rf build\n",
+		},
+		{
+			name:  "before other recipe lines",
+			input: "all: a b;@echo done\n\t@echo really done\n",
+			want:  "all: a b\n\t@echo done\n\t@echo really done\n",
+		},
+		{
+			name:  "continuation lines kept",
+			input: "all: a ; echo one \\\n\ttwo\n",
+			want:  "all: a ; echo one \\\n\ttwo\n",
+		},
+		{
+			name:  "double colon",
+			input: "y:: ; echo dbl\n",
+			want:  "y::\n\techo dbl\n",
+		},
+		{
+			name:  "double colon with prerequisites",
+			input: "y:: a b;echo dbl\n",
+			want:  "y:: a b\n\techo dbl\n",
+		},
+		{
+			name:  "no inline recipe",
+			input: "all: a\n\techo\n",
+			want:  "all: a\n\techo\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := formatter.Write(rule.Format(parser.Parse(tt.input), cfg))
+			if got != tt.want {
+				t.Errorf("want %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestInlineRecipeDisabled(t *testing.T) {
+	rule := &InlineRecipe{}
+	cfg := &config.DefaultConfig().Formatter
+
+	nodes := parser.Parse("clean: ; rm -rf build\n")
+	result := rule.Format(nodes, cfg)
+	if result[0] != nodes[0] {
+		t.Error("inline recipes should be kept when expand_inline_recipes is off")
+	}
+}
@@ -0,0 +1,227 @@
+package format
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/donaldgifford/makefmt/internal/config"
+	"github.com/donaldgifford/makefmt/internal/formatter"
+	"github.com/donaldgifford/makefmt/internal/parser"
+)
+
+// LineWrap wraps long prerequisite lists, .PHONY lists and assignment
+// values onto backslash continuation lines.
+type LineWrap struct{}
+
+// Name returns the config key for this rule.
+func (*LineWrap) Name() string {
+	return "max_line_length"
+}
+
+// Description returns a one-line summary of the rule.
+func (*LineWrap) Description() string {
+	return "Wraps long prerequisite lists and assignment values onto continuation lines."
+}
+
+// Active reports whether max_line_length is positive.
+func (*LineWrap) Active(cfg *config.FormatterConfig) bool {
+	return cfg.MaxLineLength > 0
+}
+
+// Format rewraps nodes with a line longer than cfg.MaxLineLength. Inside
+// conditionals the limit leaves room for the indentation that
+// ConditionalIndent adds later.
+func (r *LineWrap) Format(nodes []*parser.Node, cfg *config.FormatterConfig) []*parser.Node {
+	if !r.Active(cfg) {
+		return nodes
+	}
+
+	indent := strings.Repeat(" ", cfg.ContinuationIndent)
+	widths := lineWidths(nodes, cfg)
+	result := make([]*parser.Node, len(nodes))
+	for i, n := range nodes {
+		result[i] = wrapNode(n, widths[i], cfg.TabWidth, indent, cfg.AssignmentSpacing)
+	}
+	return result
+}
+
+// lineWidths returns the columns each node's lines may use under
+// cfg.MaxLineLength, leaving room for the indentation that
+// ConditionalIndent adds inside conditionals. A width of zero or less
+// means there is no limit.
+func lineWidths(nodes []*parser.Node, cfg *config.FormatterConfig) []int {
+	widths := make([]int, len(nodes))
+	for i, level := range conditionalLevels(nodes) {
+		widths[i] = cfg.MaxLineLength
+		if cfg.MaxLineLength > 0 && cfg.IndentConditionals {
+			widths[i] = max(widths[i]-level*cfg.ConditionalIndent, 1)
+		}
+	}
+	return widths
+}
+
+// wrapNode returns n laid out within width columns, or n itself if it
+// already fits or cannot be wrapped. Rules with "##" help text are left
+// alone so that help generators still find the text on the rule line.
+func wrapNode(n *parser.Node, width, tabWidth int, indent, spacing string) *parser.Node {
+	if !tooLong(n, width, tabWidth) {
+		return n
+	}
+
+	f := &n.Fields
+	var head string
+	var items []string
+
+	switch {
+	case n.Type == parser.NodeRule && f.InlineRecipe == "" && f.InlineHelp == "":
+		head = strings.Join(f.Targets, " ") + formatter.RuleSeparator(f) + " "
+		items = splitWords(strings.Join(f.Prerequisites, " "))
+		if len(f.OrderOnly) > 0 {
+			items = append(items, "|")
+			items = append(items, splitWords(strings.Join(f.OrderOnly, " "))...)
+		}
+
+	case n.Type == parser.NodeDirective && len(f.Targets) == 1:
+		head = f.Targets[0] + ": "
+		items = f.Prerequisites
+
+	case n.Type == parser.NodeAssignment:
+		before, after := operatorSpacing(n, spacing)
+		head = assignmentHead(n, before) + after
+		items = splitWords(f.VarValue)
+	}
+
+	if len(items) < 2 {
+		return n
+	}
+
+	clone := n.Clone()
+	clone.Raw = formatter.Render(wrapDoc(head, items, indent, formatter.CommentDoc(n)), width, tabWidth)
+	return clone
+}
+
+// wrapDoc lays out head and items, filling each line with as many items
+// as fit and continuing on backslash continuation lines.
+func wrapDoc(head string, items []string, indent string, comment formatter.Doc) formatter.Doc {
+	rest := make([]formatter.Doc, 0, 2*len(items))
+	for _, item := range items[1:] {
+		rest = append(rest, formatter.Group(formatter.Concat(formatter.Continuation(), formatter.Text(item))))
+	}
+
+	return formatter.Concat(
+		formatter.Text(head+items[0]),
+		formatter.Indent(indent, formatter.Concat(rest...)),
+		comment,
+	)
+}
+
+// tooLong reports whether any line of n is wider than width columns.
+func tooLong(n *parser.Node, width, tabWidth int) bool {
+	text := n.Raw
+	if text == "" {
+		text = formatter.Render(formatter.NodeDoc(n), 0, 0)
+	}
+	for line := range strings.SplitSeq(text, "\n") {
+		if formatter.Width(line, tabWidth) > width {
+			return true
+		}
+	}
+	return false
+}
+
+// assignmentHead returns an assignment's modifiers, name and operator,
+// with before between the name and the operator.
+func assignmentHead(n *parser.Node, before string) string {
+	return modifierPrefix(n) + n.Fields.VarName + before + n.Fields.AssignOp
+}
+
+// operatorSpacing returns the whitespace to write before and after an
+// assignment's operator under the assignment_spacing mode. With
+// "preserve" it is the spacing in n.Raw, or single spaces if Raw does not
+// show it.
+func operatorSpacing(n *parser.Node, mode string) (before, after string) {
+	switch mode {
+	case "no_space":
+		return "", ""
+	case "preserve":
+		if before, after, _, ok := splitFirstLine(n); ok {
+			return before, after
+		}
+	}
+	return " ", " "
+}
+
+// splitFirstLine returns the whitespace around the operator on the first
+// line of n.Raw and the text after it, and false if that line does not
+// start with the assignment's modifiers, name and operator.
+func splitFirstLine(n *parser.Node) (before, after, value string, ok bool) {
+	rest, _, _ := strings.Cut(n.Raw, "\n")
+	for _, word := range append(slices.Clone(n.Fields.Modifiers), n.Fields.VarName) {
+		if rest, ok = strings.CutPrefix(strings.TrimLeft(rest, " \t"), word); !ok {
+			return "", "", "", false
+		}
+	}
+
+	op := strings.TrimLeft(rest, " \t")
+	before = rest[:len(rest)-len(op)]
+	if rest, ok = strings.CutPrefix(op, n.Fields.AssignOp); !ok {
+		return "", "", "", false
+	}
+	value = strings.TrimLeft(rest, " \t")
+	return before, rest[:len(rest)-len(value)], value, true
+}
+
+// modifierPrefix returns the assignment's modifiers, each followed by a
+// space (e.g., "export override ").
+func modifierPrefix(n *parser.Node) string {
+	if len(n.Fields.Modifiers) == 0 {
+		return ""
+	}
+	return strings.Join(n.Fields.Modifiers, " ") + " "
+}
+
+// splitWords splits s at whitespace outside variable references and
+// quoted strings, so that no word breaks inside $(...) or "...".
+func splitWords(s string) []string {
+	var words []string
+	var quote byte
+	depth := 0
+	start := -1
+
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if quote == 0 && depth == 0 && (c == ' ' || c == '\t') {
+			if start >= 0 {
+				words = append(words, s[start:i])
+				start = -1
+			}
+			continue
+		}
+		if start < 0 {
+			start = i
+		}
+
+		switch {
+		case c == '\\':
+			i++ // Keep the escaped character.
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '$' && i+1 < len(s) && (s[i+1] == '(' || s[i+1] == '{'):
+			depth++
+			i++
+		case depth > 0 && (c == '(' || c == '{'):
+			depth++
+		case depth > 0 && (c == ')' || c == '}'):
+			depth--
+		}
+	}
+
+	if start >= 0 {
+		words = append(words, s[start:])
+	}
+	return words
+}
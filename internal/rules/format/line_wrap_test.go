@@ -0,0 +1,165 @@
+package format
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/donaldgifford/makefmt/internal/config"
+	"github.com/donaldgifford/makefmt/internal/formatter"
+	"github.com/donaldgifford/makefmt/internal/parser"
+)
+
+func TestLineWrap(t *testing.T) {
+	rule := &LineWrap{}
+	cfg := &config.DefaultConfig().Formatter
+	cfg.MaxLineLength = 30
+	cfg.ContinuationIndent = 4
+
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{
+			name:  "assignment value",
+			input: "SOURCES := alpha.c beta.c gamma.c delta.c epsilon.c\n",
+			want:  "SOURCES := alpha.c beta.c \\\n    gamma.c delta.c epsilon.c\n",
+		},
+		{
+			name:  "rule prerequisites",
+			input: "all: build test lint vet fmt | out-dir\n",
+			want:  "all: build test lint vet fmt \\\n    | out-dir\n",
+		},
+		{
+			name:  "double-colon rule",
+			input: "all:: build test lint vet fmt docs\n",
+			want:  "all:: build test lint vet \\\n    fmt docs\n",
+		},
+		{
+			name:  "phony list",
+			input: ".PHONY: build test lint vet fmt docs\n",
+			want:  ".PHONY: build test lint vet \\\n    fmt docs\n",
+		},
+		{
+			name:  "no break inside references or quotes",
+			input: "X := a $(filter %.c, $(SRCS)) \"b c d e f\"\n",
+			want:  "X := a \\\n    $(filter %.c, $(SRCS)) \\\n    \"b c d e f\"\n",
+		},
+		{
+			name:  "inline comment stays last",
+			input: "FLAGS := -Wall -Wextra -O2 # warnings\n",
+			want:  "FLAGS := -Wall -Wextra \\\n    -O2 # warnings\n",
+		},
+		{
+			name:  "rewraps continuation lines",
+			input: "SOURCES := alpha.c \\\n  beta.c gamma.c delta.c epsilon.c\n",
+			want:  "SOURCES := alpha.c beta.c \\\n    gamma.c delta.c epsilon.c\n",
+		},
+		{
+			name:  "short line kept",
+			input: "SOURCES := a.c \\\n  b.c\n",
+			want:  "SOURCES := a.c \\\n  b.c\n",
+		},
+		{
+			name:  "help text kept",
+			input: "build: alpha beta gamma delta ## Build it\n",
+			want:  "build: alpha beta gamma delta ## Build it\n",
+		},
+		{
+			name:  "inside conditional",
+			input: "ifdef X\nSOURCES := alpha.c beta.c gamma.c\nendif\n",
+			want:  "ifdef X\nSOURCES := alpha.c beta.c \\\n    gamma.c\nendif\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := formatter.Write(rule.Format(parser.Parse(tt.input), cfg))
+			if got != tt.want {
+				t.Errorf("want %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestLineWrapPreserveSpacing(t *testing.T) {
+	cfg := &config.DefaultConfig().Formatter
+	cfg.MaxLineLength = 20
+	cfg.AssignmentSpacing = "preserve"
+
+	input := "SOURCES:=alpha.c beta.c gamma.c\n"
+	want := "SOURCES:=alpha.c \\\n    beta.c gamma.c\n"
+	if got := formatter.Write((&LineWrap{}).Format(parser.Parse(input), cfg)); got != want {
+		t.Errorf("want %q, got %q", want, got)
+	}
+}
+
+func TestLineWrapWithBackslashAlign(t *testing.T) {
+	// Default backslash settings: aligned at column 79, past the limit.
+	cfg := &config.DefaultConfig().Formatter
+	cfg.MaxLineLength = 40
+
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{
+			name:  "assignment value",
+			input: "SOURCES := alpha.c beta.c gamma.c delta.c epsilon.c zeta.c\n",
+			want: "SOURCES := alpha.c beta.c gamma.c      \\\n" +
+				"    delta.c epsilon.c zeta.c\n",
+		},
+		{
+			name:  "inside conditional",
+			input: "ifdef X\nSOURCES := alpha.c beta.c gamma.c delta.c epsilon.c\nendif\n",
+			want: "ifdef X\n  SOURCES := alpha.c beta.c gamma.c    \\\n" +
+				"    delta.c epsilon.c\nendif\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// In pipeline order: backslashes are aligned after
+			// conditional indentation.
+			nodes := (&LineWrap{}).Format(parser.Parse(tt.input), cfg)
+			nodes = (&ConditionalIndent{}).Format(nodes, cfg)
+			got := formatter.Write((&BackslashAlign{}).Format(nodes, cfg))
+			if got != tt.want {
+				t.Errorf("want %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestLineWrapDisabled(t *testing.T) {
+	rule := &LineWrap{}
+	cfg := &config.DefaultConfig().Formatter
+
+	input := "SOURCES := " + "alpha.c beta.c gamma.c delta.c epsilon.c zeta.c eta.c theta.c iota.c kappa.c\n"
+	if got := formatter.Write(rule.Format(parser.Parse(input), cfg)); got != input {
+		t.Errorf("want %q, got %q", input, got)
+	}
+}
+
+func TestSplitWords(t *testing.T) {
+	tests := []struct {
+		input string
+		want  []string
+	}{
+		{"a b\tc", []string{"a", "b", "c"}},
+		{"  a   b  ", []string{"a", "b"}},
+		{"$(wildcard a b) c", []string{"$(wildcard a b)", "c"}},
+		{"${X $(Y z)} w", []string{"${X $(Y z)}", "w"}},
+		{`"a b" 'c d' e`, []string{`"a b"`, `'c d'`, "e"}},
+		{`a\ b c`, []string{`a\ b`, "c"}},
+		{"$$(date +%s) x", []string{"$$(date +%s)", "x"}},
+		{"", nil},
+	}
+
+	for _, tt := range tests {
+		if got := splitWords(tt.input); !slices.Equal(got, tt.want) {
+			t.Errorf("splitWords(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
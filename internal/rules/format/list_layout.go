@@ -0,0 +1,115 @@
+package format
+
+import (
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/donaldgifford/makefmt/internal/config"
+	"github.com/donaldgifford/makefmt/internal/formatter"
+	"github.com/donaldgifford/makefmt/internal/parser"
+)
+
+// ListLayout lays out the values of list variables, such as *_SOURCES,
+// with one item per continuation line.
+type ListLayout struct{}
+
+// Name returns the config key for this rule.
+func (*ListLayout) Name() string {
+	return "list_layout"
+}
+
+// Description returns a one-line summary of the rule.
+func (*ListLayout) Description() string {
+	return "Puts each item of list variables on its own continuation line."
+}
+
+// Active reports whether list_variables names any variables.
+func (*ListLayout) Active(cfg *config.FormatterConfig) bool {
+	return len(cfg.ListVariables) > 0
+}
+
+// Format rewrites assignments to variables matching cfg.ListVariables.
+// In "expand" layout every item gets its own line; in "collapse" layout
+// the list stays on one line when it fits within cfg.MaxLineLength.
+func (r *ListLayout) Format(nodes []*parser.Node, cfg *config.FormatterConfig) []*parser.Node {
+	if !r.Active(cfg) {
+		return nodes
+	}
+
+	indent := strings.Repeat(" ", cfg.ContinuationIndent)
+	widths := lineWidths(nodes, cfg)
+	result := make([]*parser.Node, len(nodes))
+
+	for i, n := range nodes {
+		if n.Type != parser.NodeAssignment || n.Fields.AssignOp == "!=" ||
+			!matchesAny(cfg.ListVariables, n.Fields.VarName) {
+			result[i] = n
+			continue
+		}
+
+		before, after := operatorSpacing(n, cfg.AssignmentSpacing)
+		items := listItems(n.Fields.VarValue, cfg)
+		doc := listDoc(assignmentHead(n, before), after, items, indent, cfg.ListLayout == "collapse")
+
+		clone := n.Clone()
+		clone.Raw = formatter.Render(formatter.Concat(doc, formatter.CommentDoc(n)), widths[i], cfg.TabWidth)
+		result[i] = clone
+	}
+
+	return result
+}
+
+// matchesAny reports whether name matches one of the path.Match patterns.
+func matchesAny(patterns []string, name string) bool {
+	for _, p := range patterns {
+		if ok, _ := path.Match(p, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// listItems splits a list value into items, sorted and deduplicated as
+// configured.
+func listItems(value string, cfg *config.FormatterConfig) []string {
+	items := splitWords(value)
+
+	if cfg.SortListItems {
+		slices.Sort(items)
+	}
+	if cfg.DedupeListItems {
+		seen := make(map[string]bool, len(items))
+		items = slices.DeleteFunc(items, func(item string) bool {
+			dup := seen[item]
+			seen[item] = true
+			return dup
+		})
+	}
+
+	return items
+}
+
+// listDoc lays out head followed by items, one per continuation line. A
+// collapsible list stays on one line when it fits, and a single item
+// always does. after separates the operator from a first item on the
+// same line.
+func listDoc(head, after string, items []string, indent string, collapse bool) formatter.Doc {
+	switch len(items) {
+	case 0:
+		return formatter.Text(head)
+	case 1:
+		return formatter.Text(head + after + items[0])
+	}
+
+	docs := []formatter.Doc{formatter.Break(after, " \\"), formatter.Text(items[0])}
+	for _, item := range items[1:] {
+		docs = append(docs, formatter.Continuation(), formatter.Text(item))
+	}
+	list := formatter.Concat(formatter.Text(head), formatter.Indent(indent, formatter.Concat(docs...)))
+
+	if collapse {
+		return formatter.Group(list)
+	}
+	return list
+}
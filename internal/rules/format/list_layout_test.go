@@ -0,0 +1,134 @@
+package format
+
+import (
+	"testing"
+
+	"github.com/donaldgifford/makefmt/internal/config"
+	"github.com/donaldgifford/makefmt/internal/formatter"
+	"github.com/donaldgifford/makefmt/internal/parser"
+)
+
+func TestListLayout(t *testing.T) {
+	tests := []struct {
+		name  string
+		setup func(cfg *config.FormatterConfig)
+		input string
+		want  string
+	}{
+		{
+			name:  "expand",
+			input: "GO_SOURCES := main.go util.go\n",
+			want:  "GO_SOURCES := \\\n    main.go \\\n    util.go\n",
+		},
+		{
+			name:  "unmatched variable",
+			input: "CFLAGS := -O2 -g\n",
+			want:  "CFLAGS := -O2 -g\n",
+		},
+		{
+			name:  "single item",
+			input: "GO_SOURCES := \\\n  main.go\n",
+			want:  "GO_SOURCES := main.go\n",
+		},
+		{
+			name:  "references stay whole",
+			input: "GO_SOURCES += $(wildcard cmd/*.go) main.go # all\n",
+			want:  "GO_SOURCES += \\\n    $(wildcard cmd/*.go) \\\n    main.go # all\n",
+		},
+		{
+			name:  "modifiers",
+			input: "export GO_SOURCES = a.go b.go\n",
+			want:  "export GO_SOURCES = \\\n    a.go \\\n    b.go\n",
+		},
+		{
+			name:  "shell assignment skipped",
+			input: "GO_SOURCES != find . -name '*.go'\n",
+			want:  "GO_SOURCES != find . -name '*.go'\n",
+		},
+		{
+			name: "collapse when it fits",
+			setup: func(cfg *config.FormatterConfig) {
+				cfg.ListLayout = "collapse"
+				cfg.MaxLineLength = 40
+			},
+			input: "GO_SOURCES := \\\n    main.go \\\n    util.go\n",
+			want:  "GO_SOURCES := main.go util.go\n",
+		},
+		{
+			name: "collapse expands long lists",
+			setup: func(cfg *config.FormatterConfig) {
+				cfg.ListLayout = "collapse"
+				cfg.MaxLineLength = 20
+			},
+			input: "GO_SOURCES := main.go util.go\n",
+			want:  "GO_SOURCES := \\\n    main.go \\\n    util.go\n",
+		},
+		{
+			name: "sort and dedupe",
+			setup: func(cfg *config.FormatterConfig) {
+				cfg.SortListItems = true
+				cfg.DedupeListItems = true
+			},
+			input: "GO_SOURCES := b.go a.go b.go\n",
+			want:  "GO_SOURCES := \\\n    a.go \\\n    b.go\n",
+		},
+		{
+			name: "dedupe keeps first occurrence",
+			setup: func(cfg *config.FormatterConfig) {
+				cfg.DedupeListItems = true
+			},
+			input: "GO_SOURCES := b.go a.go b.go\n",
+			want:  "GO_SOURCES := \\\n    b.go \\\n    a.go\n",
+		},
+		{
+			name: "no space",
+			setup: func(cfg *config.FormatterConfig) {
+				cfg.AssignmentSpacing = "no_space"
+				cfg.ListLayout = "collapse"
+			},
+			input: "GO_SOURCES := a.go b.go\n",
+			want:  "GO_SOURCES:=a.go b.go\n",
+		},
+		{
+			name: "preserve keeps operator spacing",
+			setup: func(cfg *config.FormatterConfig) {
+				cfg.AssignmentSpacing = "preserve"
+			},
+			input: "export GO_SOURCES+=a.go b.go\n",
+			want:  "export GO_SOURCES+= \\\n    a.go \\\n    b.go\n",
+		},
+		{
+			name: "preserve keeps uneven spacing",
+			setup: func(cfg *config.FormatterConfig) {
+				cfg.AssignmentSpacing = "preserve"
+				cfg.ListLayout = "collapse"
+			},
+			input: "GO_SOURCES\t:=  a.go \\\n  b.go\n",
+			want:  "GO_SOURCES\t:=  a.go b.go\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.DefaultConfig().Formatter
+			cfg.ListVariables = []string{"*_SOURCES"}
+			if tt.setup != nil {
+				tt.setup(cfg)
+			}
+
+			got := formatter.Write((&ListLayout{}).Format(parser.Parse(tt.input), cfg))
+			if got != tt.want {
+				t.Errorf("want %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestListLayoutDisabled(t *testing.T) {
+	cfg := &config.DefaultConfig().Formatter
+
+	input := "GO_SOURCES := main.go util.go\n"
+	if got := formatter.Write((&ListLayout{}).Format(parser.Parse(input), cfg)); got != input {
+		t.Errorf("want %q, got %q", input, got)
+	}
+}
@@ -16,9 +16,19 @@ func (*TrailingWhitespace) Name() string {
 	return "trim_trailing_whitespace"
 }
 
+// Description returns a one-line summary of the rule.
+func (*TrailingWhitespace) Description() string {
+	return "Removes trailing spaces and tabs from every line."
+}
+
+// Active reports whether trim_trailing_whitespace is set.
+func (*TrailingWhitespace) Active(cfg *config.FormatterConfig) bool {
+	return cfg.TrimTrailingWhitespace
+}
+
 // Format strips trailing whitespace from all nodes.
-func (*TrailingWhitespace) Format(nodes []*parser.Node, cfg *config.FormatterConfig) []*parser.Node {
-	if !cfg.TrimTrailingWhitespace {
+func (r *TrailingWhitespace) Format(nodes []*parser.Node, cfg *config.FormatterConfig) []*parser.Node {
+	if !r.Active(cfg) {
 		return nodes
 	}
 
@@ -41,6 +51,7 @@ func trimNode(n *parser.Node) *parser.Node {
 	clone.Fields.Text = strings.TrimRight(clone.Fields.Text, " \t")
 	clone.Fields.VarValue = strings.TrimRight(clone.Fields.VarValue, " \t")
 	clone.Fields.InlineHelp = strings.TrimRight(clone.Fields.InlineHelp, " \t")
+	clone.Fields.InlineRecipe = strings.TrimRight(clone.Fields.InlineRecipe, " \t")
 	clone.Fields.Condition = strings.TrimRight(clone.Fields.Condition, " \t")
 
 	// Recurse into children.
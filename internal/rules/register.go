@@ -12,9 +12,21 @@ func init() {
 	RegisterFormatRule(&format.BlankLines{})
 	RegisterFormatRule(&format.AssignmentSpacing{})
 
-	// Phase 6 rules (5-8):
-	RegisterFormatRule(&format.BackslashAlign{})
+	// Opt-in layout rules (5-8), before indentation is applied:
+	RegisterFormatRule(&format.InlineRecipe{})
+	RegisterFormatRule(&format.ListLayout{})
+	RegisterFormatRule(&format.LineWrap{})
+	RegisterFormatRule(&format.ContinuationIndent{})
+
+	// Phase 6 rules (9-11). Backslashes are aligned after conditional
+	// indentation, which shifts only the first line of a node:
 	RegisterFormatRule(&format.CommentSpacing{})
 	RegisterFormatRule(&format.ConditionalIndent{})
+	RegisterFormatRule(&format.BackslashAlign{})
+
+	// Opt-in alignment rules (12), after indentation is applied:
+	RegisterFormatRule(&format.InlineCommentAlign{})
+
+	// Guard rule (13), always last:
 	RegisterFormatRule(&format.BannerPreserve{})
 }
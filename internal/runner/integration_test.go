@@ -38,6 +38,8 @@ func TestIntegrationStdinFormat(t *testing.T) {
 	bin := binaryPath(t)
 
 	cmd := exec.CommandContext(t.Context(), bin)
+	// Run outside the repository so its makefmt.yml is not discovered.
+	cmd.Dir = t.TempDir()
 	cmd.Stdin = strings.NewReader("VAR:=val\n")
 	out, err := cmd.Output()
 	if err != nil {
@@ -52,6 +54,8 @@ func TestIntegrationCheckFormatted(t *testing.T) {
 	bin := binaryPath(t)
 
 	cmd := exec.CommandContext(t.Context(), bin, "-check")
+	// Run outside the repository so its makefmt.yml is not discovered.
+	cmd.Dir = t.TempDir()
 	cmd.Stdin = strings.NewReader("VAR := val\n")
 	err := cmd.Run()
 	if err != nil {
@@ -63,6 +67,8 @@ func TestIntegrationCheckUnformatted(t *testing.T) {
 	bin := binaryPath(t)
 
 	cmd := exec.CommandContext(t.Context(), bin, "-check")
+	// Run outside the repository so its makefmt.yml is not discovered.
+	cmd.Dir = t.TempDir()
 	cmd.Stdin = strings.NewReader("VAR:=val\n")
 	err := cmd.Run()
 	if err == nil {
@@ -80,6 +86,8 @@ func TestIntegrationDiff(t *testing.T) {
 	bin := binaryPath(t)
 
 	cmd := exec.CommandContext(t.Context(), bin, "-diff")
+	// Run outside the repository so its makefmt.yml is not discovered.
+	cmd.Dir = t.TempDir()
 	cmd.Stdin = strings.NewReader("VAR:=val\n")
 	out, err := cmd.CombinedOutput()
 	if err == nil {
@@ -195,3 +203,149 @@ func TestIntegrationMultipleFiles(t *testing.T) {
 		}
 	}
 }
+
+func TestIntegrationConfigValidate(t *testing.T) {
+	bin := binaryPath(t)
+	dir := t.TempDir()
+
+	good := filepath.Join(dir, "good.yml")
+	bad := filepath.Join(dir, "bad.yml")
+	if err := os.WriteFile(good, []byte("formatter:\n  max_blank_lines: 1\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(bad, []byte("formatter:\n  max_blank_line: 1\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	cmd := exec.CommandContext(t.Context(), bin, "config", "validate", good)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("valid config: %v\n%s", err, out)
+	}
+
+	cmd = exec.CommandContext(t.Context(), bin, "config", "validate", bad)
+	var stderr strings.Builder
+	cmd.Stderr = &stderr
+	err := cmd.Run()
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) || exitErr.ExitCode() != 1 {
+		t.Fatalf("invalid config: expected exit 1, got %v", err)
+	}
+	want := bad + `:2:3: unknown field "max_blank_line" (did you mean max_blank_lines?)`
+	if !strings.Contains(stderr.String(), want) {
+		t.Errorf("stderr: got %q, want it to contain %q", stderr.String(), want)
+	}
+}
+
+func TestIntegrationConfigInitShow(t *testing.T) {
+	bin := binaryPath(t)
+	dir := t.TempDir()
+	path := filepath.Join(dir, "makefmt.yml")
+
+	cmd := exec.CommandContext(t.Context(), bin, "config", "init", path)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("config init: %v\n%s", err, out)
+	}
+
+	// A second init must not overwrite the file.
+	cmd = exec.CommandContext(t.Context(), bin, "config", "init", path)
+	var exitErr *exec.ExitError
+	if err := cmd.Run(); !errors.As(err, &exitErr) || exitErr.ExitCode() != 2 {
+		t.Errorf("config init on existing file: expected exit 2, got %v", err)
+	}
+
+	if err := os.WriteFile(path, []byte("formatter:\n  tab_width: 8\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cmd = exec.CommandContext(t.Context(), bin, "config", "show", filepath.Join(dir, "Makefile"))
+	out, err := cmd.Output()
+	if err != nil {
+		t.Fatalf("config show: %v", err)
+	}
+	for _, want := range []string{"tab_width: 8 # " + path, "max_blank_lines: 2 # default"} {
+		if !strings.Contains(string(out), want) {
+			t.Errorf("config show: output missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestIntegrationEnvironment(t *testing.T) {
+	bin := binaryPath(t)
+	dir := t.TempDir()
+
+	configPath := filepath.Join(dir, "env.yml")
+	if err := os.WriteFile(configPath, []byte("formatter:\n  assignment_spacing: no_space\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		env  []string
+		args []string
+		want string
+	}{
+		{"config path", []string{"MAKEFMT_CONFIG=" + configPath}, nil, "A=1\n"},
+		{"setting", []string{"MAKEFMT_ASSIGNMENT_SPACING=preserve"}, nil, "A =  1\n"},
+		{"env beats file", []string{"MAKEFMT_CONFIG=" + configPath, "MAKEFMT_ASSIGNMENT_SPACING=space"}, nil, "A = 1\n"},
+		{"flag beats env", []string{"MAKEFMT_ASSIGNMENT_SPACING=preserve"}, []string{"-set", "assignment_spacing=no_space"}, "A=1\n"},
+		{"unrelated variable", []string{"MAKEFMT_VERSION=1"}, nil, "A = 1\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := exec.CommandContext(t.Context(), bin, tt.args...)
+			cmd.Dir = dir
+			cmd.Env = append(os.Environ(), tt.env...)
+			cmd.Stdin = strings.NewReader("A =  1\n")
+			out, err := cmd.Output()
+			if err != nil {
+				t.Fatalf("run: %v", err)
+			}
+			if string(out) != tt.want {
+				t.Errorf("got %q, want %q", string(out), tt.want)
+			}
+		})
+	}
+}
+
+func TestIntegrationRulesList(t *testing.T) {
+	bin := binaryPath(t)
+
+	cmd := exec.CommandContext(t.Context(), bin, "rules", "list",
+		"-set", "rules.max_blank_lines=false",
+		"-set", "trim_trailing_whitespace=false",
+		"-set", "max_line_length=80")
+	cmd.Dir = t.TempDir()
+	out, err := cmd.Output()
+	if err != nil {
+		t.Fatalf("rules list: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(string(out)), "\n")
+	if len(lines) < 2 || !strings.HasPrefix(lines[0], "NAME") {
+		t.Fatalf("unexpected output:\n%s", out)
+	}
+
+	// want maps a rule to its DEFAULT and STATE columns. A rule is off
+	// when formatter.rules turns it off or its own setting does.
+	want := map[string][2]string{
+		"max_blank_lines":          {"enabled", "disabled"},
+		"trim_trailing_whitespace": {"enabled", "disabled"},
+		"max_line_length":          {"disabled", "enabled"},
+		"align_inline_comments":    {"disabled", "disabled"},
+		"insert_final_newline":     {"enabled", "enabled"},
+	}
+	for _, line := range lines[1:] {
+		fields := strings.Fields(line)
+		w, ok := want[fields[0]]
+		if !ok {
+			continue
+		}
+		delete(want, fields[0])
+		if fields[1] != w[0] || fields[2] != w[1] {
+			t.Errorf("%s: got %q, want %s by default and %s", fields[0], line, w[0], w[1])
+		}
+	}
+	for name := range want {
+		t.Errorf("%s not listed:\n%s", name, out)
+	}
+}
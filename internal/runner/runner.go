@@ -2,13 +2,18 @@
 package runner
 
 import (
+	"encoding/json"
 	"fmt"
 	"io"
+	"math"
 	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
 
 	"github.com/donaldgifford/makefmt/internal/config"
 	"github.com/donaldgifford/makefmt/internal/formatter"
-	"github.com/donaldgifford/makefmt/internal/parser"
+	"github.com/donaldgifford/makefmt/internal/git"
 	"github.com/donaldgifford/makefmt/internal/rules"
 	"github.com/donaldgifford/makefmt/pkg/diff"
 )
@@ -29,8 +34,31 @@ type Options struct {
 	ConfigPath string
 	Quiet      bool
 	Verbose    bool
-	Stdout     io.Writer
-	Stderr     io.Writer
+
+	// ChangedSince limits processing to Makefiles that differ from this
+	// git ref (including untracked files).
+	ChangedSince string
+	// Staged limits processing to Makefiles with changes in the git index.
+	Staged bool
+	// LinesChangedOnly applies only the formatting changes that overlap
+	// lines changed relative to ChangedSince (or HEAD), or to the staged
+	// changes with Staged.
+	LinesChangedOnly bool
+
+	// Lines limits formatting changes to these line ranges. Rules still
+	// see the whole file for context.
+	Lines []diff.LineRange
+
+	// Edits prints the formatting changes as JSON text edits instead of
+	// writing the formatted result.
+	Edits bool
+
+	// Settings override individual config values for every file. They
+	// take precedence over config files.
+	Settings []config.Setting
+
+	Stdout io.Writer
+	Stderr io.Writer
 }
 
 // Run executes the format pipeline and returns an exit code.
@@ -42,22 +70,58 @@ func Run(opts *Options) int {
 		opts.Stderr = os.Stderr
 	}
 
-	cfg, err := config.Load(opts.ConfigPath)
-	if err != nil {
+	// Configs are resolved per file so that files in different subtrees
+	// pick up their own makefmt.yml. An explicit config is checked up
+	// front so a bad path fails before any file is processed.
+	formatRules := rules.FormatRules()
+	resolver := config.NewResolver(opts.ConfigPath, formatter.RuleNames(formatRules))
+	if err := resolver.Set(opts.Settings...); err != nil {
 		writeErr(opts.Stderr, "makefmt: %v\n", err)
 		return ExitError
 	}
+	if opts.ConfigPath != "" {
+		if _, err := resolver.ForDir("."); err != nil {
+			writeErr(opts.Stderr, "makefmt: %v\n", err)
+			return ExitError
+		}
+	}
 
-	formatRules := rules.FormatRules()
+	files := opts.Files
+	if opts.ChangedSince != "" || opts.Staged {
+		var err error
+		files, err = changedMakefiles(opts)
+		if err != nil {
+			writeErr(opts.Stderr, "makefmt: %v\n", err)
+			return ExitError
+		}
+		if len(files) == 0 {
+			return ExitOK
+		}
+	}
 
 	// stdin mode: no files given.
-	if len(opts.Files) == 0 {
+	if len(files) == 0 {
+		if opts.LinesChangedOnly {
+			writeErr(opts.Stderr, "makefmt: -lines-changed-only requires file arguments or a git selection\n")
+			return ExitError
+		}
+		cfg, err := resolver.ForDir(".")
+		if err != nil {
+			writeErr(opts.Stderr, "makefmt: %v\n", err)
+			return ExitError
+		}
 		return runStdin(opts, cfg, formatRules)
 	}
 
 	exitCode := ExitOK
-	for _, path := range opts.Files {
-		code := runFile(opts, cfg, formatRules, path)
+	for _, path := range files {
+		code := ExitError
+		cfg, err := resolver.ForFile(path)
+		if err != nil {
+			writeErr(opts.Stderr, "makefmt: %s: %v\n", path, err)
+		} else {
+			code = runFile(opts, cfg, formatRules, path)
+		}
 		if code > exitCode {
 			exitCode = code
 		}
@@ -73,7 +137,11 @@ func runStdin(opts *Options, cfg *config.Config, formatRules []formatter.FormatR
 	}
 
 	input := string(src)
+	warnSuppressions(opts, "<stdin>", input, formatRules)
 	output := formatInput(input, cfg, formatRules)
+	if len(opts.Lines) > 0 {
+		output = diff.Restrict(input, output, opts.Lines)
+	}
 
 	if opts.Check {
 		if input != output {
@@ -91,6 +159,10 @@ func runStdin(opts *Options, cfg *config.Config, formatRules []formatter.FormatR
 		return ExitOK
 	}
 
+	if opts.Edits {
+		return writeEdits(opts, "<stdin>", input, output)
+	}
+
 	writeOut(opts.Stdout, output)
 	return ExitOK
 }
@@ -103,8 +175,21 @@ func runFile(opts *Options, cfg *config.Config, formatRules []formatter.FormatRu
 	}
 
 	input := string(src)
+	warnSuppressions(opts, path, input, formatRules)
 	output := formatInput(input, cfg, formatRules)
 
+	if opts.LinesChangedOnly && input != output {
+		ranges, err := changedLines(opts, path)
+		if err != nil {
+			writeErr(opts.Stderr, "makefmt: %s: %v\n", path, err)
+			return ExitError
+		}
+		output = diff.Restrict(input, output, ranges)
+	}
+	if len(opts.Lines) > 0 {
+		output = diff.Restrict(input, output, opts.Lines)
+	}
+
 	if opts.Verbose {
 		writeErr(opts.Stderr, "%s\n", path)
 	}
@@ -128,6 +213,10 @@ func runFile(opts *Options, cfg *config.Config, formatRules []formatter.FormatRu
 		return ExitOK
 	}
 
+	if opts.Edits {
+		return writeEdits(opts, path, input, output)
+	}
+
 	// Write mode (default for file args).
 	if input == output {
 		return ExitOK
@@ -141,10 +230,141 @@ func runFile(opts *Options, cfg *config.Config, formatRules []formatter.FormatRu
 	return ExitOK
 }
 
+// warnSuppressions reports suppression comments in input that name
+// unknown rules, unless Quiet is set. Formatting continues regardless.
+func warnSuppressions(opts *Options, path, input string, formatRules []formatter.FormatRule) {
+	if opts.Quiet {
+		return
+	}
+	for _, w := range formatter.CheckSuppressions(input, formatRules) {
+		writeErr(opts.Stderr, "makefmt: %s:%d: %s\n", path, w.Line, w.Message)
+	}
+}
+
+// fileEdits is the JSON form of the edits for one input, written as a
+// single line of output by writeEdits.
+type fileEdits struct {
+	Path  string      `json:"path"`
+	Edits []diff.Edit `json:"edits"`
+}
+
+// writeEdits prints the minimal edits from input to output as one JSON
+// object per line. Offsets are byte offsets into input.
+func writeEdits(opts *Options, path, input, output string) int {
+	edits := diff.Edits(input, output)
+	if edits == nil {
+		edits = []diff.Edit{}
+	}
+
+	data, err := json.Marshal(fileEdits{Path: path, Edits: edits})
+	if err != nil {
+		writeErr(opts.Stderr, "makefmt: encoding edits for %s: %v\n", path, err)
+		return ExitError
+	}
+	writeOut(opts.Stdout, string(data)+"\n")
+
+	if len(edits) > 0 {
+		return ExitFormatDiff
+	}
+	return ExitOK
+}
+
+// changedMakefiles returns the Makefiles selected by the git options. When
+// files were given explicitly, only those that are also changed are kept.
+func changedMakefiles(opts *Options) ([]string, error) {
+	var changed []string
+	var err error
+	if opts.Staged {
+		changed, err = git.StagedFiles()
+	} else {
+		changed, err = git.ChangedFiles(opts.ChangedSince)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	wanted := make(map[string]bool, len(opts.Files))
+	for _, f := range opts.Files {
+		wanted[absPath(f)] = true
+	}
+
+	var files []string
+	for _, f := range changed {
+		if len(wanted) > 0 && !wanted[absPath(f)] {
+			continue
+		}
+		if len(wanted) == 0 && !IsMakefile(f) {
+			continue
+		}
+		files = append(files, f)
+	}
+	return files, nil
+}
+
+// absPath returns the absolute form of path, or path itself if it cannot
+// be resolved.
+func absPath(path string) string {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return filepath.Clean(path)
+	}
+	return abs
+}
+
+// changedLines returns the lines of path changed according to the git
+// options: the staged changes with Staged, and otherwise the changes
+// relative to ChangedSince (or HEAD).
+func changedLines(opts *Options, path string) ([]diff.LineRange, error) {
+	if opts.Staged {
+		return git.StagedLines(path)
+	}
+	if opts.ChangedSince != "" {
+		return git.ChangedLines(opts.ChangedSince, path)
+	}
+	return git.ChangedLines("HEAD", path)
+}
+
+// IsMakefile reports whether path looks like a Makefile by name:
+// Makefile, makefile, GNUmakefile, or a .mk/.make extension.
+func IsMakefile(path string) bool {
+	base := filepath.Base(path)
+	switch base {
+	case "Makefile", "makefile", "GNUmakefile":
+		return true
+	}
+	ext := strings.ToLower(filepath.Ext(base))
+	return ext == ".mk" || ext == ".make"
+}
+
 func formatInput(input string, cfg *config.Config, formatRules []formatter.FormatRule) string {
-	nodes := parser.Parse(input)
-	formatted := formatter.Run(nodes, &cfg.Formatter, formatRules)
-	return formatter.Write(formatted)
+	return formatter.Format(input, &cfg.Formatter, formatRules)
+}
+
+// ParseLineRange parses a 1-indexed, inclusive line range of the form
+// "a:b". Either bound may be omitted to mean the start or end of the file.
+func ParseLineRange(s string) (diff.LineRange, error) {
+	startStr, endStr, found := strings.Cut(s, ":")
+	if !found {
+		return diff.LineRange{}, fmt.Errorf("invalid line range %q: want start:end", s)
+	}
+
+	r := diff.LineRange{Start: 1, End: math.MaxInt}
+	var err error
+	if startStr != "" {
+		if r.Start, err = strconv.Atoi(startStr); err != nil {
+			return diff.LineRange{}, fmt.Errorf("invalid line range %q: %w", s, err)
+		}
+	}
+	if endStr != "" {
+		if r.End, err = strconv.Atoi(endStr); err != nil {
+			return diff.LineRange{}, fmt.Errorf("invalid line range %q: %w", s, err)
+		}
+	}
+
+	if r.Start < 1 || r.End < r.Start {
+		return diff.LineRange{}, fmt.Errorf("invalid line range %q: want 1 <= start <= end", s)
+	}
+	return r, nil
 }
 
 // writeOut writes to stdout.
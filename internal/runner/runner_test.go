@@ -2,10 +2,17 @@ package runner
 
 import (
 	"bytes"
+	"encoding/json"
+	"math"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
+	"github.com/donaldgifford/makefmt/internal/config"
+	"github.com/donaldgifford/makefmt/internal/testutil"
+	"github.com/donaldgifford/makefmt/pkg/diff"
+
 	_ "github.com/donaldgifford/makefmt/internal/rules" // Register rules via init().
 )
 
@@ -215,3 +222,251 @@ func TestRunVerbose(t *testing.T) {
 		t.Errorf("verbose mode should print filename to stderr, got: %s", stderr.String())
 	}
 }
+
+func TestRunWarnsUnknownSuppressedRule(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "test.mk")
+	if err := os.WriteFile(path, []byte("# makefmt: disable=max_blank_line\nVAR := val\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	code := Run(&Options{
+		Files:  []string{path},
+		Check:  true,
+		Stdout: &stdout,
+		Stderr: &stderr,
+	})
+
+	if code != ExitOK {
+		t.Errorf("exit code: got %d, want %d", code, ExitOK)
+	}
+	want := "makefmt: " + path + `:1: unknown rule "max_blank_line" in suppression comment (did you mean max_blank_lines?)` + "\n"
+	if stderr.String() != want {
+		t.Errorf("stderr: got %q, want %q", stderr.String(), want)
+	}
+}
+
+func TestRunChangedSinceLinesChangedOnly(t *testing.T) {
+	dir := testutil.InitRepo(t)
+	testutil.WriteFile(t, dir, "Makefile", "A:=1\nB:=2\nC:=3\n")
+	testutil.WriteFile(t, dir, "untouched.mk", "X:=1\n")
+	testutil.Git(t, dir, "add", ".")
+	testutil.Git(t, dir, "commit", "-q", "-m", "initial")
+	testutil.WriteFile(t, dir, "Makefile", "A:=1\nB:=22\nC:=3\n")
+
+	t.Chdir(dir)
+
+	var stdout, stderr bytes.Buffer
+	code := Run(&Options{
+		ChangedSince:     "HEAD",
+		LinesChangedOnly: true,
+		Write:            true,
+		Stdout:           &stdout,
+		Stderr:           &stderr,
+	})
+	if code != ExitOK {
+		t.Fatalf("exit code: got %d, want %d (stderr: %s)", code, ExitOK, stderr.String())
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "Makefile"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "A:=1\nB := 22\nC:=3\n"; string(data) != want {
+		t.Errorf("Makefile: got %q, want %q", string(data), want)
+	}
+
+	data, err = os.ReadFile(filepath.Join(dir, "untouched.mk"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "X:=1\n"; string(data) != want {
+		t.Errorf("untouched.mk should not be processed: got %q, want %q", string(data), want)
+	}
+}
+
+func TestRunStagedLinesChangedOnly(t *testing.T) {
+	dir := testutil.InitRepo(t)
+	testutil.WriteFile(t, dir, "Makefile", "A:=1\nB:=2\nC:=3\n")
+	testutil.Git(t, dir, "add", ".")
+	testutil.Git(t, dir, "commit", "-q", "-m", "initial")
+
+	// Stage a change to B, then leave an unstaged change to C.
+	testutil.WriteFile(t, dir, "Makefile", "A:=1\nB:=22\nC:=3\n")
+	testutil.Git(t, dir, "add", "Makefile")
+	testutil.WriteFile(t, dir, "Makefile", "A:=1\nB:=22\nC:=33\n")
+
+	t.Chdir(dir)
+
+	var stdout, stderr bytes.Buffer
+	code := Run(&Options{
+		Staged:           true,
+		LinesChangedOnly: true,
+		Write:            true,
+		Stdout:           &stdout,
+		Stderr:           &stderr,
+	})
+	if code != ExitOK {
+		t.Fatalf("exit code: got %d, want %d (stderr: %s)", code, ExitOK, stderr.String())
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "Makefile"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "A:=1\nB := 22\nC:=33\n"; string(data) != want {
+		t.Errorf("Makefile: got %q, want %q", string(data), want)
+	}
+}
+
+func TestParseLineRange(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    diff.LineRange
+		wantErr bool
+	}{
+		{input: "3:7", want: diff.LineRange{Start: 3, End: 7}},
+		{input: "5:5", want: diff.LineRange{Start: 5, End: 5}},
+		{input: ":4", want: diff.LineRange{Start: 1, End: 4}},
+		{input: "4:", want: diff.LineRange{Start: 4, End: math.MaxInt}},
+		{input: "7", wantErr: true},
+		{input: "0:3", wantErr: true},
+		{input: "5:2", wantErr: true},
+		{input: "a:b", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParseLineRange(tt.input)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("expected error, got %+v", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRunLines(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "test.mk")
+	if err := os.WriteFile(path, []byte("A:=1\nB:=2\nC:=3\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	code := Run(&Options{
+		Files:  []string{path},
+		Write:  true,
+		Lines:  []diff.LineRange{{Start: 2, End: 2}},
+		Stdout: &stdout,
+		Stderr: &stderr,
+	})
+	if code != ExitOK {
+		t.Fatalf("exit code: got %d, want %d", code, ExitOK)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "A:=1\nB := 2\nC:=3\n"; string(data) != want {
+		t.Errorf("file content: got %q, want %q", string(data), want)
+	}
+}
+
+func TestRunEdits(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "test.mk")
+	if err := os.WriteFile(path, []byte("VAR:=val\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	code := Run(&Options{
+		Files:  []string{path},
+		Edits:  true,
+		Stdout: &stdout,
+		Stderr: &stderr,
+	})
+	if code != ExitFormatDiff {
+		t.Errorf("exit code: got %d, want %d", code, ExitFormatDiff)
+	}
+
+	var got struct {
+		Path  string      `json:"path"`
+		Edits []diff.Edit `json:"edits"`
+	}
+	if err := json.Unmarshal(stdout.Bytes(), &got); err != nil {
+		t.Fatalf("decoding %q: %v", stdout.String(), err)
+	}
+	if got.Path != path {
+		t.Errorf("path: got %q, want %q", got.Path, path)
+	}
+	want := []diff.Edit{{Start: 3, End: 5, NewText: " := "}}
+	if len(got.Edits) != 1 || got.Edits[0] != want[0] {
+		t.Errorf("edits: got %+v, want %+v", got.Edits, want)
+	}
+
+	// The file must not be modified.
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "VAR:=val\n" {
+		t.Errorf("file modified: got %q", string(data))
+	}
+}
+
+func TestRunSettings(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "test.mk")
+	if err := os.WriteFile(path, []byte("A := 1\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cfgPath := filepath.Join(dir, "makefmt.yml")
+	if err := os.WriteFile(cfgPath, []byte("formatter:\n  assignment_spacing: preserve\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	code := Run(&Options{
+		Files:      []string{path},
+		ConfigPath: cfgPath,
+		Settings:   []config.Setting{{Key: "assignment_spacing", Value: "no_space", Source: "--set"}},
+		Stdout:     &stdout,
+		Stderr:     &stderr,
+	})
+	if code != ExitOK {
+		t.Fatalf("exit code: got %d, want %d (stderr: %s)", code, ExitOK, stderr.String())
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "A:=1\n"; string(data) != want {
+		t.Errorf("file content: got %q, want %q", string(data), want)
+	}
+
+	stderr.Reset()
+	code = Run(&Options{
+		Files:    []string{path},
+		Settings: []config.Setting{{Key: "max_blank_line", Value: "1", Source: "--set"}},
+		Stdout:   &stdout,
+		Stderr:   &stderr,
+	})
+	if code != ExitError {
+		t.Errorf("invalid setting: exit code got %d, want %d", code, ExitError)
+	}
+	if !strings.Contains(stderr.String(), "did you mean max_blank_lines?") {
+		t.Errorf("invalid setting: stderr %q", stderr.String())
+	}
+}
@@ -0,0 +1,153 @@
+package diff
+
+import (
+	"cmp"
+	"slices"
+	"strings"
+	"unicode/utf8"
+)
+
+// Edit replaces the bytes oldText[Start:End] with NewText.
+type Edit struct {
+	Start   int    `json:"start"`
+	End     int    `json:"end"`
+	NewText string `json:"newText"`
+}
+
+// Edits returns the minimal edits that transform oldText into newText, in
+// ascending order of Start. Offsets refer to oldText, so the edits must
+// be applied together (see Apply) rather than one after another.
+// Returns nil if the inputs are identical.
+//
+// Changed lines are found with the Myers algorithm; each change is then
+// narrowed to the bytes that actually differ, so an editor applying the
+// edits disturbs as little of the buffer as possible.
+func Edits(oldText, newText string) []Edit {
+	if oldText == newText {
+		return nil
+	}
+
+	oldLines := splitLines(oldText)
+	newLines := splitLines(newText)
+	offsets := lineOffsets(oldLines)
+	edits := myers(oldLines, newLines)
+
+	var result []Edit
+	for i := 0; i < len(edits); {
+		if edits[i].kind == editEqual {
+			i++
+			continue
+		}
+
+		end := i
+		for end < len(edits) && edits[end].kind != editEqual {
+			end++
+		}
+
+		for _, c := range splitChange(edits, i, end) {
+			e := c.toEdit(edits, i, offsets, newLines)
+			result = append(result, narrow(oldText, e))
+		}
+		i = end
+	}
+
+	return result
+}
+
+// toEdit converts a change unit into a byte-offset edit against the old
+// text. first is the index in all of the first edit of the enclosing run
+// and is used to locate the insertion point of pure insertions.
+func (c *change) toEdit(all []edit, first int, offsets []int, newLines []string) Edit {
+	start, end := -1, -1
+	var b strings.Builder
+	for _, e := range c.edits {
+		switch e.kind {
+		case editDelete:
+			if start < 0 {
+				start = offsets[e.oldIdx]
+			}
+			end = offsets[e.oldIdx+1]
+		case editInsert:
+			b.WriteString(newLines[e.newIdx])
+		}
+	}
+
+	if start < 0 {
+		// Insertion only: insert before the next old line.
+		start = offsets[insertionLine(all, first)]
+		end = start
+	}
+
+	return Edit{Start: start, End: end, NewText: b.String()}
+}
+
+// narrow shrinks e to exclude the leading and trailing bytes its old and
+// new text have in common. Boundaries are kept on UTF-8 rune starts.
+func narrow(oldText string, e Edit) Edit {
+	old := oldText[e.Start:e.End]
+	repl := e.NewText
+
+	prefix := 0
+	for prefix < len(old) && prefix < len(repl) && old[prefix] == repl[prefix] {
+		prefix++
+	}
+	for prefix > 0 && prefix < len(old) && !utf8.RuneStart(old[prefix]) {
+		prefix--
+	}
+
+	suffix := 0
+	for suffix < len(old)-prefix && suffix < len(repl)-prefix &&
+		old[len(old)-1-suffix] == repl[len(repl)-1-suffix] {
+		suffix++
+	}
+	for suffix > 0 && suffix < len(old)-prefix && !utf8.RuneStart(old[len(old)-suffix]) {
+		suffix--
+	}
+
+	return Edit{
+		Start:   e.Start + prefix,
+		End:     e.End - suffix,
+		NewText: repl[prefix : len(repl)-suffix],
+	}
+}
+
+// insertionLine returns the 0-indexed old line before which the insertion
+// run beginning at all[first] takes place.
+func insertionLine(all []edit, first int) int {
+	for i := first - 1; i >= 0; i-- {
+		if all[i].oldIdx >= 0 {
+			return all[i].oldIdx + 1
+		}
+	}
+	return 0
+}
+
+// lineOffsets returns the byte offset at which each line starts, plus a
+// final entry for the end of the text.
+func lineOffsets(lines []string) []int {
+	offsets := make([]int, len(lines)+1)
+	for i, line := range lines {
+		offsets[i+1] = offsets[i] + len(line)
+	}
+	return offsets
+}
+
+// Apply applies non-overlapping edits to text. Edit offsets refer to the
+// original text; the edits may be given in any order.
+func Apply(text string, edits []Edit) string {
+	sorted := make([]Edit, len(edits))
+	copy(sorted, edits)
+	slices.SortStableFunc(sorted, func(a, b Edit) int {
+		return cmp.Compare(a.Start, b.Start)
+	})
+
+	var b strings.Builder
+	last := 0
+	for _, e := range sorted {
+		b.WriteString(text[last:e.Start])
+		b.WriteString(e.NewText)
+		last = e.End
+	}
+	b.WriteString(text[last:])
+	return b.String()
+}
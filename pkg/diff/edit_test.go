@@ -0,0 +1,102 @@
+package diff
+
+import "testing"
+
+func TestEditsApplyRoundTrip(t *testing.T) {
+	tests := []struct {
+		name         string
+		old, updated string
+	}{
+		{"identical", "a\nb\n", "a\nb\n"},
+		{"modify", "a\nb\nc\n", "a\nB\nc\n"},
+		{"insert middle", "a\nc\n", "a\nb\nc\n"},
+		{"insert start", "b\n", "a\nb\n"},
+		{"insert end", "a\n", "a\nb\n"},
+		{"delete", "a\nb\nc\n", "a\nc\n"},
+		{"from empty", "", "a\n"},
+		{"to empty", "a\n", ""},
+		{"no final newline", "a\nb", "a\nb\n"},
+		{"mixed", "x:=1\n\n\n\ny:=2\nz\n", "x := 1\n\ny := 2\nz\nw\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			edits := Edits(tt.old, tt.updated)
+			if tt.old == tt.updated && edits != nil {
+				t.Errorf("expected no edits for identical input, got %v", edits)
+			}
+			if got := Apply(tt.old, edits); got != tt.updated {
+				t.Errorf("Apply(Edits):\nwant: %q\ngot:  %q\nedits: %+v", tt.updated, got, edits)
+			}
+		})
+	}
+}
+
+func TestEditsOffsets(t *testing.T) {
+	edits := Edits("a\nb\nc\n", "a\nB\nc\n")
+	want := []Edit{{Start: 2, End: 3, NewText: "B"}}
+	if len(edits) != len(want) || edits[0] != want[0] {
+		t.Errorf("Edits = %+v, want %+v", edits, want)
+	}
+}
+
+func TestEditsMinimal(t *testing.T) {
+	tests := []struct {
+		name         string
+		old, updated string
+		want         []Edit
+	}{
+		{
+			name:    "assignment spacing",
+			old:     "VAR:=val\n",
+			updated: "VAR := val\n",
+			want:    []Edit{{Start: 3, End: 5, NewText: " := "}},
+		},
+		{
+			name:    "trailing whitespace",
+			old:     "a  \nb\n",
+			updated: "a\nb\n",
+			want:    []Edit{{Start: 1, End: 3, NewText: ""}},
+		},
+		{
+			name:    "separate lines",
+			old:     "a:=1\nb\nc:=2\n",
+			updated: "a := 1\nb\nc := 2\n",
+			want: []Edit{
+				{Start: 1, End: 3, NewText: " := "},
+				{Start: 8, End: 10, NewText: " := "},
+			},
+		},
+		{
+			name:    "multibyte rune boundary",
+			old:     "# é\n",
+			updated: "# è\n",
+			want:    []Edit{{Start: 2, End: 4, NewText: "è"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			edits := Edits(tt.old, tt.updated)
+			if len(edits) != len(tt.want) {
+				t.Fatalf("Edits = %+v, want %+v", edits, tt.want)
+			}
+			for i := range edits {
+				if edits[i] != tt.want[i] {
+					t.Errorf("edit %d = %+v, want %+v", i, edits[i], tt.want[i])
+				}
+			}
+			if got := Apply(tt.old, edits); got != tt.updated {
+				t.Errorf("Apply(Edits) = %q, want %q", got, tt.updated)
+			}
+		})
+	}
+}
+
+func TestEditsInsertionPoint(t *testing.T) {
+	edits := Edits("a\nc\n", "a\nb\nc\n")
+	want := []Edit{{Start: 2, End: 2, NewText: "b\n"}}
+	if len(edits) != len(want) || edits[0] != want[0] {
+		t.Errorf("Edits = %+v, want %+v", edits, want)
+	}
+}
@@ -0,0 +1,134 @@
+package diff
+
+import "strings"
+
+// LineRange is an inclusive, 1-indexed range of lines.
+type LineRange struct {
+	Start int
+	End   int
+}
+
+// Overlaps reports whether r shares at least one line with other.
+func (r LineRange) Overlaps(other LineRange) bool {
+	return r.Start <= other.End && other.Start <= r.End
+}
+
+// Restrict returns newText with only those changes applied whose span in
+// oldText overlaps one of the given line ranges. All other changes are
+// reverted to the corresponding oldText lines.
+//
+// A change that only inserts lines is considered to span the old lines on
+// either side of the insertion point, so insertions directly adjacent to a
+// range are kept.
+func Restrict(oldText, newText string, ranges []LineRange) string {
+	if oldText == newText {
+		return newText
+	}
+
+	oldLines := splitLines(oldText)
+	newLines := splitLines(newText)
+	edits := myers(oldLines, newLines)
+
+	var b strings.Builder
+	for i := 0; i < len(edits); {
+		if edits[i].kind == editEqual {
+			b.WriteString(oldLines[edits[i].oldIdx])
+			i++
+			continue
+		}
+
+		end := i
+		for end < len(edits) && edits[end].kind != editEqual {
+			end++
+		}
+
+		for _, c := range splitChange(edits, i, end) {
+			keep := overlapsAny(c.span, ranges)
+			for _, e := range c.edits {
+				switch {
+				case keep && e.kind == editInsert:
+					b.WriteString(newLines[e.newIdx])
+				case !keep && e.kind == editDelete:
+					b.WriteString(oldLines[e.oldIdx])
+				}
+			}
+		}
+		i = end
+	}
+
+	return b.String()
+}
+
+// change is a group of delete/insert edits that is applied or reverted
+// as a unit.
+type change struct {
+	span  LineRange
+	edits []edit
+}
+
+// splitChange breaks the change edits[start:end] into independent units.
+// When the change deletes and inserts the same number of lines, each old
+// line is paired with its replacement so that line-by-line rewrites can
+// be restricted individually. Otherwise the whole change is one unit.
+func splitChange(edits []edit, start, end int) []change {
+	var deletes, inserts []edit
+	for _, e := range edits[start:end] {
+		if e.kind == editDelete {
+			deletes = append(deletes, e)
+		} else {
+			inserts = append(inserts, e)
+		}
+	}
+
+	if len(deletes) == 0 || len(deletes) != len(inserts) {
+		return []change{{span: changeSpan(edits, start, end), edits: edits[start:end]}}
+	}
+
+	changes := make([]change, len(deletes))
+	for i, d := range deletes {
+		line := d.oldIdx + 1
+		changes[i] = change{
+			span:  LineRange{Start: line, End: line},
+			edits: []edit{d, inserts[i]},
+		}
+	}
+	return changes
+}
+
+// changeSpan returns the 1-indexed old-line span covered by the change
+// edits[start:end]. Pure insertions span the lines around the insertion
+// point.
+func changeSpan(edits []edit, start, end int) LineRange {
+	span := LineRange{Start: -1, End: -1}
+	for _, e := range edits[start:end] {
+		if e.kind != editDelete {
+			continue
+		}
+		if span.Start < 0 {
+			span.Start = e.oldIdx + 1
+		}
+		span.End = e.oldIdx + 1
+	}
+	if span.Start >= 0 {
+		return span
+	}
+
+	// Insertion only: find the number of old lines preceding it.
+	before := 0
+	for i := start - 1; i >= 0; i-- {
+		if edits[i].oldIdx >= 0 {
+			before = edits[i].oldIdx + 1
+			break
+		}
+	}
+	return LineRange{Start: max(before, 1), End: before + 1}
+}
+
+func overlapsAny(span LineRange, ranges []LineRange) bool {
+	for _, r := range ranges {
+		if span.Overlaps(r) {
+			return true
+		}
+	}
+	return false
+}
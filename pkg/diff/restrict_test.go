@@ -0,0 +1,93 @@
+package diff
+
+import "testing"
+
+func TestRestrict(t *testing.T) {
+	old := "a:=1\nb:=2\nc:=3\nd:=4\n"
+	updated := "a := 1\nb := 2\nc := 3\nd := 4\n"
+
+	tests := []struct {
+		name   string
+		ranges []LineRange
+		want   string
+	}{
+		{"no ranges", nil, old},
+		{"single line", []LineRange{{2, 2}}, "a:=1\nb := 2\nc:=3\nd:=4\n"},
+		{"span", []LineRange{{3, 4}}, "a:=1\nb:=2\nc := 3\nd := 4\n"},
+		{"everything", []LineRange{{1, 100}}, updated},
+		{"outside file", []LineRange{{10, 12}}, old},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Restrict(old, updated, tt.ranges)
+			if got != tt.want {
+				t.Errorf("Restrict:\nwant: %q\ngot:  %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestRestrictSeparateHunks(t *testing.T) {
+	old := "x:=1\nkeep\nkeep\nkeep\nkeep\ny:=2\n"
+	updated := "x := 1\nkeep\nkeep\nkeep\nkeep\ny := 2\n"
+
+	got := Restrict(old, updated, []LineRange{{6, 6}})
+	want := "x:=1\nkeep\nkeep\nkeep\nkeep\ny := 2\n"
+	if got != want {
+		t.Errorf("want: %q\ngot:  %q", want, got)
+	}
+}
+
+func TestRestrictDeletion(t *testing.T) {
+	old := "a\n\n\n\nb\n"
+	updated := "a\n\n\nb\n"
+
+	// The deleted blank line is line 2, 3 or 4 depending on the diff; any
+	// range over the blank run must keep the deletion.
+	if got := Restrict(old, updated, []LineRange{{2, 4}}); got != updated {
+		t.Errorf("overlapping range: want %q, got %q", updated, got)
+	}
+	if got := Restrict(old, updated, []LineRange{{1, 1}}); got != old {
+		t.Errorf("non-overlapping range: want %q, got %q", old, got)
+	}
+}
+
+func TestRestrictInsertion(t *testing.T) {
+	old := "a\nb\n"
+	updated := "a\nb\nc\n"
+
+	if got := Restrict(old, updated, []LineRange{{2, 2}}); got != updated {
+		t.Errorf("adjacent range: want %q, got %q", updated, got)
+	}
+	if got := Restrict(old, updated, []LineRange{{1, 1}}); got != old {
+		t.Errorf("distant range: want %q, got %q", old, got)
+	}
+}
+
+func TestRestrictUnevenChange(t *testing.T) {
+	// Joining a continuation line and rewriting the next line deletes
+	// three lines and inserts two. The lines cannot be paired, so any
+	// range touching the change keeps all of it.
+	old := "SRCS := a \\\n    b\nX:=1\n"
+	updated := "SRCS := a b\nX := 1\n"
+
+	tests := []struct {
+		name   string
+		ranges []LineRange
+		want   string
+	}{
+		{"first line", []LineRange{{1, 1}}, updated},
+		{"continuation line", []LineRange{{2, 2}}, updated},
+		{"last line", []LineRange{{3, 3}}, updated},
+		{"outside change", []LineRange{{5, 6}}, old},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Restrict(old, updated, tt.ranges); got != tt.want {
+				t.Errorf("want: %q\ngot:  %q", tt.want, got)
+			}
+		})
+	}
+}
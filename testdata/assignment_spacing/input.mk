@@ -4,3 +4,6 @@ DESCRIPTION:= A project
 GO ?= go
 GO_PACKAGE:=github.com/foo/bar
 VERSION+=extra
+export GOFLAGS:=-mod=mod
+override  CFLAGS +=-g
+export PATH
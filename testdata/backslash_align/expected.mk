@@ -1 +1,4 @@
-SOURCES := main.go  	utils.go  	handler.go
+SOURCES :=                                                                    \
+	main.go                                                                   \
+	utils.go                                                                  \
+	handler.go
@@ -3,3 +3,8 @@ ifdef DEBUG
 else
   CFLAGS := -O2
 endif
+
+ifdef DEBUG
+  export override LDFLAGS := -s
+  unexport SECRET
+endif
@@ -3,3 +3,8 @@ CFLAGS := -g
 else
 CFLAGS := -O2
 endif
+
+ifdef DEBUG
+export override LDFLAGS:=-s
+unexport SECRET
+endif
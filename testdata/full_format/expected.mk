@@ -26,9 +26,9 @@ test: ## Run tests
 
 release: ## Create release (use with TAG=v1.0.0)
 	@ $(MAKE) --no-print-directory log-$@
-	@if [ -z "$(TAG)" ]; then                                                    \
-		echo "Error: TAG is required";                                              \
-			exit 1;                                                                    \
+	@if [ -z "$(TAG)" ]; then \
+		echo "Error: TAG is required"; \
+			exit 1; \
 	fi
 	git tag -a $(TAG) -m "Release $(TAG)"
 
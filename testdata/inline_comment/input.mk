@@ -0,0 +1,10 @@
+CC:=gcc       # compiler
+CFLAGS += -O2    # optimize
+include config.mk # local settings
+
+build: main.o # link
+	$(CC) -o $@ $^ # shell comment, not touched
+
+ifdef DEBUG # debug builds
+CFLAGS+=-g # symbols
+endif
@@ -0,0 +1,7 @@
+clean: ; rm -rf build
+all: a b;@echo done
+	@echo really done
+
+ifdef DEBUG
+  debug: ; @echo debug
+endif
@@ -0,0 +1,12 @@
+build:
+	# install deps
+	#no space after hash
+# make comment between recipe lines
+	  # indented shell comment
+	go build ./...
+# trailing make comment
+
+test:
+	##@ not a section header
+	# =====
+	go test ./...
@@ -0,0 +1,25 @@
+A:=1
+
+# makefmt: off
+TABLE_A  :=   alpha   
+TABLE_BB :=   beta
+
+
+
+TABLE_C  :=   gamma
+# makefmt: on
+B:=2
+
+# makefmt: ignore-next-line
+C   =   3
+D=4
+
+# makefmt: disable=assignment_spacing
+E:=5   
+# makefmt: enable=assignment_spacing
+F:=6
+
+build:
+	# makefmt: ignore-next-line
+	echo   hi   
+	echo bye   